@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// cmdAmendVersion rewrites the version of the most recent release in both
+// CHANGELOG.md and config.json, leaving the release notes untouched.
+func cmdAmendVersion(p paths, args []string) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: changesets amend-version <version>")
+	}
+
+	ver, err := semver.NewVersion(strings.TrimPrefix(args[0], "v"))
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", ErrInvalidVersion, args[0], err)
+	}
+	newVer := "v" + ver.String()
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	changelogPath := filepath.Join(p.root, changelogFile)
+	data, err := os.ReadFile(changelogPath)
+	if err != nil {
+		return fmt.Errorf("failed to read CHANGELOG.md: %w", err)
+	}
+
+	content, oldVer, err := amendLatestVersion(string(data), newVer)
+	if err != nil {
+		return err
+	}
+
+	if err := writeChangelog(changelogPath, content); err != nil {
+		return err
+	}
+
+	cfg.Version = newVer
+	if err := saveConfig(p.config, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Amended %s to %s\n", oldVer, newVer)
+	return nil
+}
+
+// amendLatestVersion replaces the version in the topmost release header with
+// newVer, along with its compare link if there is one. It returns the updated
+// content and the version that was replaced.
+func amendLatestVersion(content, newVer string) (string, string, error) {
+	sections := parseChangelogSections(content)
+	if len(sections) == 0 {
+		return "", "", fmt.Errorf("CHANGELOG.md has no release sections to amend")
+	}
+
+	top := sections[0]
+	oldVer := top.version
+
+	headerEnd := top.start + strings.IndexByte(content[top.start:], '\n')
+	if headerEnd < top.start {
+		headerEnd = len(content)
+	}
+	header := strings.Replace(content[top.start:headerEnd], oldVer, newVer, 1)
+	content = content[:top.start] + header + content[headerEnd:]
+
+	// Keep the compare link pointing at the amended version.
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "["+oldVer+"]:") {
+			line = "[" + newVer + "]:" + strings.TrimPrefix(line, "["+oldVer+"]:")
+			lines[i] = strings.Replace(line, "..."+oldVer, "..."+newVer, 1)
+		}
+	}
+
+	return strings.Join(lines, "\n"), oldVer, nil
+}
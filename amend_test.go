@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAmendLatestVersion(t *testing.T) {
+	content := "# Changelog\n\n## v1.1.0 - 2026-02-01\n\n- Breaking\n\n## v1.0.0 - 2026-01-01\n\n- Initial\n"
+
+	got, old, err := amendLatestVersion(content, "v2.0.0")
+	if err != nil {
+		t.Fatalf("amendLatestVersion failed: %v", err)
+	}
+	if old != "v1.1.0" {
+		t.Errorf("expected old version v1.1.0, got %s", old)
+	}
+
+	expected := "# Changelog\n\n## v2.0.0 - 2026-02-01\n\n- Breaking\n\n## v1.0.0 - 2026-01-01\n\n- Initial\n"
+	if got != expected {
+		t.Errorf("unexpected content.\nExpected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestAmendLatestVersionCompareLink(t *testing.T) {
+	content := "# Changelog\n\n## [v1.1.0] - 2026-02-01\n\n- Breaking\n\n[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n"
+
+	got, _, err := amendLatestVersion(content, "v2.0.0")
+	if err != nil {
+		t.Fatalf("amendLatestVersion failed: %v", err)
+	}
+	if !strings.Contains(got, "## [v2.0.0] - 2026-02-01") {
+		t.Errorf("expected amended header, got:\n%s", got)
+	}
+	if !strings.Contains(got, "[v2.0.0]: https://example.com/compare/v1.0.0...v2.0.0") {
+		t.Errorf("expected amended compare link, got:\n%s", got)
+	}
+}
+
+func TestAmendLatestVersionNoSections(t *testing.T) {
+	if _, _, err := amendLatestVersion("# Changelog\n", "v2.0.0"); err == nil {
+		t.Fatal("expected error when there are no sections")
+	}
+}
+
+func TestCmdAmendVersion(t *testing.T) {
+	p := setupProject(t, "v1.1.0")
+	changelog := filepath.Join(p.root, "CHANGELOG.md")
+	os.WriteFile(changelog, []byte("# Changelog\n\n## v1.1.0 - 2026-02-01\n\n- Breaking\n"), 0644)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdAmendVersion(p, []string{"2.0.0"})
+	})
+	if err != nil {
+		t.Fatalf("cmdAmendVersion failed: %v", err)
+	}
+	if !strings.Contains(output, "Amended v1.1.0 to v2.0.0") {
+		t.Errorf("unexpected output: %q", output)
+	}
+
+	data, _ := os.ReadFile(changelog)
+	if !strings.Contains(string(data), "## v2.0.0 - 2026-02-01") {
+		t.Errorf("expected amended header, got:\n%s", data)
+	}
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v2.0.0" {
+		t.Errorf("expected config v2.0.0, got %s", cfg.Version)
+	}
+}
+
+func TestCmdAmendVersionInvalid(t *testing.T) {
+	p := setupProject(t, "v1.1.0")
+
+	err := cmdAmendVersion(p, []string{"two"})
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("expected ErrInvalidVersion, got %v", err)
+	}
+}
+
+func TestCmdAmendVersionMissingArgument(t *testing.T) {
+	p := setupProject(t, "v1.1.0")
+	if err := cmdAmendVersion(p, nil); err == nil {
+		t.Fatal("expected error for missing version argument")
+	}
+}
+
+func TestCmdAmendVersionNoChangelog(t *testing.T) {
+	p := setupProject(t, "v1.1.0")
+	if err := cmdAmendVersion(p, []string{"v2.0.0"}); err == nil {
+		t.Fatal("expected error when CHANGELOG.md is missing")
+	}
+}
+
+func TestCmdAmendVersionNoSections(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	os.WriteFile(filepath.Join(p.root, "CHANGELOG.md"), []byte("# Changelog\n"), 0644)
+
+	if err := cmdAmendVersion(p, []string{"v1.0.0"}); err == nil {
+		t.Fatal("expected error when the changelog has no sections")
+	}
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v0.0.0" {
+		t.Errorf("config should be untouched, got %s", cfg.Version)
+	}
+}
+
+func TestCmdAmendVersionNoDir(t *testing.T) {
+	p := newPaths(t.TempDir())
+	if err := cmdAmendVersion(p, []string{"v1.0.0"}); err == nil {
+		t.Fatal("expected error when .changesets doesn't exist")
+	}
+}
+
+func TestCmdAmendVersionConfigMissing(t *testing.T) {
+	p := newPaths(t.TempDir())
+	os.MkdirAll(p.changes, 0755)
+	if err := cmdAmendVersion(p, []string{"v1.0.0"}); err == nil {
+		t.Fatal("expected error when config is missing")
+	}
+}
@@ -0,0 +1,673 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// changelogOptions controls how a release section is rendered.
+type changelogOptions struct {
+	compareLinks    bool   // wrap the version header in brackets so it resolves to a compare link
+	template        string // text/template source; empty uses defaultChangelogTemplate
+	showAuthors     bool   // include changeset authors in entries
+	showSubjects    bool   // include the subject of the commit that added each changeset
+	repoURL         string // repository URL for compare links
+	previousVersion string // version the release compares against; empty when unknown
+	channel         string // prerelease channel shown in the header; empty for stable
+	summaryRender   string // how multi-line summaries are rendered; see renderSummary
+
+	// date is the release date shown in the header; the zero value means today.
+	date time.Time
+
+	// commitSHA looks up the commit that added a changeset file; nil asks git.
+	// Setting it renders changesets that are not on disk without running git.
+	commitSHA func(path string) (string, error)
+
+	// commitSubject looks up the subject of that commit; nil asks git.
+	commitSubject func(path string) (string, error)
+}
+
+// entrySHA returns the short SHA shown for cs, or "" when it is unknown.
+func (o changelogOptions) entrySHA(cs *changeset) string {
+	lookup := o.commitSHA
+	if lookup == nil {
+		lookup = getFileCommitSHA
+	}
+	sha, _ := lookup(cs.filepath)
+	return sha
+}
+
+// entrySubject returns the commit subject shown for cs, or "" when subjects
+// are disabled or the changeset is not committed yet.
+func (o changelogOptions) entrySubject(cs *changeset) string {
+	if !o.showSubjects {
+		return ""
+	}
+	lookup := o.commitSubject
+	if lookup == nil {
+		lookup = getFileCommitSubject
+	}
+	subject, _ := lookup(cs.filepath)
+	return subject
+}
+
+// defaultChangelogTemplate renders a release section grouped by change type:
+//
+//	## v1.2.0 - 2026-02-14
+//
+//	### Minor Changes
+//
+//	- a1b2c3d: Added support for custom changelog templates
+//
+// Each entry is rendered by the "entry" template, which preview uses on its own.
+const defaultChangelogTemplate = `## {{if .CompareLinks}}[{{.Version}}]{{else}}{{.Version}}{{end}} - {{.Date}}{{if .Channel}} ({{.Channel}}){{end}}
+{{range .Groups}}
+### {{.Title}}
+
+{{range .Entries}}{{template "entry" .}}{{end}}{{end}}
+{{- define "entry"}}- {{if .SHA}}{{.SHA}}: {{end}}{{.Summary}}{{if .Subject}} — "{{.Subject}}"{{end}}{{if .Author}} ({{.Author}}){{end}}
+{{if .Details}}{{indent 2 .Details}}
+{{end}}{{end}}`
+
+// entryTemplate is the name of the template that renders a single changelog
+// entry, when the changelog template defines one.
+const entryTemplate = "entry"
+
+// parseChangelogTemplate parses a changelog template, or the built-in one when
+// text is empty.
+func parseChangelogTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultChangelogTemplate
+	}
+	tmpl, err := template.New("changelog").Funcs(changelogFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse changelog template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// changelogFuncs are the helper functions available to changelog templates.
+var changelogFuncs = template.FuncMap{
+	"indent": indent,
+}
+
+// indent prefixes every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// changelogData is the data passed to changelog templates.
+type changelogData struct {
+	Version         string           // version being released, e.g. "v1.2.0"
+	PreviousVersion string           // version released before this one; empty when unknown
+	CompareURL      string           // URL comparing PreviousVersion to Version; empty without repoURL
+	Date            string           // release date as YYYY-MM-DD
+	CompareLinks    bool             // whether the header should be a compare link reference
+	Channel         string           // prerelease channel, e.g. "beta"; empty for stable
+	Groups          []changelogGroup // non-empty groups, most impactful first
+}
+
+// changelogGroup is the set of changes sharing a bump type.
+type changelogGroup struct {
+	Bump    string // "major", "minor", "patch", or "none"
+	Title   string // e.g. "Minor Changes"
+	Entries []changelogEntry
+}
+
+// changelogEntry is a single changeset in a release.
+type changelogEntry struct {
+	SHA     string // short commit SHA that added the changeset; empty when unknown
+	Summary string
+	Subject string // subject of the commit that added the changeset; empty unless showCommitSubject is enabled
+	Author  string // who to credit; empty unless showAuthors is enabled
+	Details string // optional details block, rendered indented under the entry
+}
+
+// newChangelogData groups changes by bump type for rendering.
+func newChangelogData(ver string, now time.Time, changes []*changeset, opts changelogOptions) changelogData {
+	data := changelogData{
+		Version:         ver,
+		PreviousVersion: opts.previousVersion,
+		CompareURL:      compareURL(opts.repoURL, opts.previousVersion, ver),
+		Date:            now.Format(releaseDateLayout),
+		CompareLinks:    opts.compareLinks,
+		Channel:         opts.channel,
+	}
+
+	for _, g := range []struct {
+		bump  bumpType
+		title string
+	}{
+		{major, "Major Changes"},
+		{minor, "Minor Changes"},
+		{patch, "Patch Changes"},
+		{none, "Other Changes"},
+	} {
+		group := changelogGroup{Bump: string(g.bump), Title: g.title}
+		var collapsed []string // labels in order of first appearance
+		counts := make(map[string]int)
+		for _, cs := range changes {
+			if cs.bump != g.bump {
+				continue
+			}
+			if cs.collapse != "" {
+				if counts[cs.collapse] == 0 {
+					collapsed = append(collapsed, cs.collapse)
+				}
+				counts[cs.collapse]++
+				continue
+			}
+			entry := changelogEntry{SHA: opts.entrySHA(cs), Subject: opts.entrySubject(cs), Details: cs.details}
+			if opts.showAuthors {
+				entry.Author = cs.author
+			}
+			// A bulleted summary becomes one entry per bullet; the details
+			// block stays with the first.
+			for i, summary := range summaryEntries(cs.summary) {
+				entry.Summary = renderSummary(summary, opts.summaryRender)
+				if i > 0 {
+					entry.Details = ""
+				}
+				group.Entries = append(group.Entries, entry)
+			}
+		}
+		for _, label := range collapsed {
+			group.Entries = append(group.Entries, changelogEntry{
+				Summary: fmt.Sprintf("%s (%d)", label, counts[label]),
+			})
+		}
+		if len(group.Entries) > 0 {
+			data.Groups = append(data.Groups, group)
+		}
+	}
+
+	return data
+}
+
+// Values of the summaryRender config field.
+const (
+	summaryRenderFirstLine = "first-line" // keep only the first line, unless the rest holds a code block (default)
+	summaryRenderCollapse  = "collapse"   // join the lines into one
+	summaryRenderIndent    = "indent"     // indent the other lines as a block under the entry
+)
+
+// renderSummary fits a multi-line summary into a single list item, so the
+// changelog list is not broken by the lines after the first. An empty mode
+// means summaryRenderFirstLine, which still indents the rest like
+// summaryRenderIndent when it holds a code block, so the code is not lost.
+func renderSummary(summary, mode string) string {
+	first, rest, ok := strings.Cut(summary, "\n")
+	if !ok {
+		return summary
+	}
+
+	switch {
+	case mode == summaryRenderCollapse:
+		var parts []string
+		for _, line := range strings.Split(summary, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				parts = append(parts, line)
+			}
+		}
+		return strings.Join(parts, " ")
+	case mode == summaryRenderIndent || hasCodeFence(rest):
+		return first + "\n" + indent(2, rest)
+	default:
+		return first
+	}
+}
+
+// summaryDropsLines reports whether renderSummary in mode leaves non-blank
+// lines of summary out of the changelog.
+func summaryDropsLines(summary, mode string) bool {
+	if mode != "" && mode != summaryRenderFirstLine {
+		return false
+	}
+	_, rest, ok := strings.Cut(summary, "\n")
+	return ok && strings.TrimSpace(rest) != "" && !hasCodeFence(rest)
+}
+
+// findDroppedSummaries returns an error for each changeset whose summary
+// loses lines in the changelog under mode, so they can be reported as
+// warnings before the text is silently left out.
+func findDroppedSummaries(changes []*changeset, mode string) []error {
+	var dropped []error
+	for _, cs := range changes {
+		for _, summary := range summaryEntries(cs.summary) {
+			if summaryDropsLines(summary, mode) {
+				dropped = append(dropped, &fileError{
+					file: filepath.Base(cs.filepath),
+					err:  fmt.Errorf("summary lines after the first are left out of the changelog; set summaryRender to %s or %s to keep them", summaryRenderCollapse, summaryRenderIndent),
+				})
+				break
+			}
+		}
+	}
+	return dropped
+}
+
+// printDroppedSummaries writes a warning for each changeset whose summary
+// loses lines in the changelog under mode.
+func printDroppedSummaries(w io.Writer, changes []*changeset, mode string) {
+	for _, e := range findDroppedSummaries(changes, mode) {
+		fmt.Fprintf(w, "warning: %s\n", e)
+	}
+}
+
+// changelogSection is a release section found in an existing changelog.
+type changelogSection struct {
+	version string // version from the header, e.g. "v1.2.0"
+	date    string // date from the header; empty when absent
+	start   int    // byte offset of the "## " header line
+	end     int    // byte offset just past the end of the section
+}
+
+// sectionHeaderRe matches release headers written by buildChangelogSection,
+// with or without brackets: "## v1.2.0 - 2026-02-14" or "## [v1.2.0] - 2026-02-14".
+// The version must look like one (semver or calver, "v" optional), so other
+// headings such as "## Unreleased" or a preamble are not taken for releases.
+var sectionHeaderRe = regexp.MustCompile(`^## \[?(v?\d+(?:\.\d+){1,2}(?:[-+][0-9A-Za-z.+-]*)?)\]?(?:\s+-\s+(\S+))?(?:\s|$)`)
+
+// parseChangelogSections returns the release sections of a changelog in file order
+// (newest first). Each section runs until the next release header, or until the
+// link definitions at the bottom of the file. When the changelog has a
+// changelogInsertMarker, everything above it is preamble and is skipped.
+func parseChangelogSections(content string) []changelogSection {
+	limit := linkBlockStart(content)
+
+	start := 0
+	if marker := strings.Index(content, changelogInsertMarker); marker >= 0 && marker < limit {
+		start = limit
+		if i := strings.IndexByte(content[marker:limit], '\n'); i >= 0 {
+			start = marker + i + 1
+		}
+	}
+
+	var sections []changelogSection
+	for offset := start; offset < limit; {
+		next := limit
+		if i := strings.IndexByte(content[offset:limit], '\n'); i >= 0 {
+			next = offset + i + 1
+		}
+		line := strings.TrimRight(content[offset:next], "\n")
+
+		if m := sectionHeaderRe.FindStringSubmatch(line); m != nil {
+			if len(sections) > 0 {
+				sections[len(sections)-1].end = offset
+			}
+			sections = append(sections, changelogSection{version: m[1], date: m[2], start: offset, end: limit})
+		}
+
+		offset = next
+	}
+
+	return sections
+}
+
+// findChangelogSection looks up the section in content whose version matches the
+// header of the new section.
+func findChangelogSection(content, section string) (changelogSection, bool) {
+	m := sectionHeaderRe.FindStringSubmatch(section)
+	if m == nil {
+		return changelogSection{}, false
+	}
+
+	for _, s := range parseChangelogSections(content) {
+		if s.version == m[1] {
+			return s, true
+		}
+	}
+	return changelogSection{}, false
+}
+
+// changelogArchivePath returns the archive file for the changelog at path,
+// e.g. CHANGELOG.archive.md for CHANGELOG.md.
+func changelogArchivePath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".archive" + ext
+}
+
+// archiveChangelogSections keeps the newest max release sections of content
+// and moves the older ones to the top of archive, the content of the archive
+// file named name, starting it with a heading when empty. The link
+// definitions of archived versions move along with their sections. Both are
+// returned unchanged when max is zero or not exceeded.
+func archiveChangelogSections(content, archive, name string, max int) (string, string, error) {
+	sections := parseChangelogSections(content)
+	if max <= 0 || len(sections) <= max {
+		return content, archive, nil
+	}
+
+	start, end := sections[max].start, sections[len(sections)-1].end
+	moved := strings.TrimRight(content[start:end], "\n") + "\n"
+	archive, err := insertChangelogSection(archive, moved, "# "+defaultChangelogTitle+" Archive", name, false)
+	if err != nil {
+		return "", "", err
+	}
+
+	archived := make(map[string]bool)
+	for _, s := range sections[max:] {
+		archived[s.version] = true
+	}
+	var keptLinks, movedLinks []string
+	for _, line := range strings.Split(strings.TrimRight(content[end:], "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if label, _, ok := strings.Cut(line, "]:"); ok && archived[strings.TrimPrefix(label, "[")] {
+			movedLinks = append(movedLinks, line)
+		} else {
+			keptLinks = append(keptLinks, line)
+		}
+	}
+	// insertCompareLink puts each link first, so add the oldest first to keep
+	// the newest on top.
+	for i := len(movedLinks) - 1; i >= 0; i-- {
+		archive = insertCompareLink(archive, movedLinks[i])
+	}
+
+	kept := content[:start]
+	if len(keptLinks) > 0 {
+		kept += strings.Join(keptLinks, "\n") + "\n"
+	}
+	return kept, archive, nil
+}
+
+// readChangelogHistory reads the changelog at path together with its archive
+// file, if any, so commands that look at past releases also see the archived
+// ones. The archived sections follow the changelog's own, and the link
+// definitions of both stay at the bottom.
+func readChangelogHistory(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	content := string(data)
+
+	data, err = os.ReadFile(changelogArchivePath(path))
+	if err != nil {
+		return content, nil
+	}
+	archive := string(data)
+	sections := parseChangelogSections(archive)
+	if len(sections) == 0 {
+		return content, nil
+	}
+
+	limit, archiveLimit := linkBlockStart(content), sections[len(sections)-1].end
+	history := strings.TrimRight(content[:limit], "\n") + "\n\n" + strings.TrimRight(archive[sections[0].start:archiveLimit], "\n") + "\n"
+	var links []string
+	for _, block := range []string{content[limit:], archive[archiveLimit:]} {
+		if block = strings.TrimRight(block, "\n"); block != "" {
+			links = append(links, block)
+		}
+	}
+	if len(links) > 0 {
+		history += "\n" + strings.Join(links, "\n") + "\n"
+	}
+	return history, nil
+}
+
+// linkBlockStart returns the byte offset where the trailing block of link
+// definitions begins, or len(content) when there is none.
+func linkBlockStart(content string) int {
+	trimmed := strings.TrimRight(content, "\n")
+	start := len(content)
+	for len(trimmed) > 0 {
+		idx := strings.LastIndexByte(trimmed, '\n')
+		line := trimmed[idx+1:]
+		if !linkDefinitionRe.MatchString(line) {
+			break
+		}
+		start = idx + 1
+		if idx < 0 {
+			break
+		}
+		trimmed = trimmed[:idx]
+	}
+	return start
+}
+
+// linkDefinitionRe matches a markdown link reference definition such as
+// "[v1.2.0]: https://github.com/owner/repo/compare/v1.1.0...v1.2.0".
+var linkDefinitionRe = regexp.MustCompile(`^\[[^\]]+\]:\s+\S+`)
+
+// compareURL returns the URL comparing prev to next, or an empty string when
+// there is no repository URL or previous version.
+func compareURL(repoURL, prev, next string) string {
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	if repoURL == "" || prev == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/compare/%s...%s", repoURL, prev, next)
+}
+
+// compareLink returns the link reference definition comparing prev to next,
+// or an empty string when there is no repository URL or previous version.
+func compareLink(repoURL, prev, next string) string {
+	url := compareURL(repoURL, prev, next)
+	if url == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s]: %s", next, url)
+}
+
+// insertCompareLink adds link to the link block at the bottom of the changelog,
+// keeping the newest link first. A new block is started when there is none, and
+// an existing definition with the same label is replaced.
+func insertCompareLink(content, link string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	// Walk back over the trailing link definitions to find where the block starts.
+	start := len(lines)
+	for start > 0 && linkDefinitionRe.MatchString(lines[start-1]) {
+		start--
+	}
+
+	label := link[:strings.Index(link, "]:")+2]
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], label) {
+			lines[i] = link
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+
+	if start == len(lines) {
+		return strings.Join(lines, "\n") + "\n\n" + link + "\n"
+	}
+
+	out := append([]string{}, lines[:start]...)
+	out = append(out, link)
+	out = append(out, lines[start:]...)
+	return strings.Join(out, "\n") + "\n"
+}
+
+// addCompareLink inserts a compare link into the changelog file at path.
+func addCompareLink(path, link string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CHANGELOG.md: %w", err)
+	}
+
+	return writeChangelog(path, insertCompareLink(string(data), link))
+}
+
+// writeChangelog writes a changelog to path with its spacing normalized.
+func writeChangelog(path, content string) error {
+	if err := os.WriteFile(path, []byte(normalizeChangelog(content)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// normalizeChangelog tidies the spacing that builds up as sections are
+// inserted and replaced: runs of three or more blank lines collapse to one,
+// and the content ends with exactly one newline. A double blank line is kept
+// as written, as are blank lines inside fenced code blocks.
+func normalizeChangelog(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	var blanks []string
+	inFence := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" && !inFence {
+			blanks = append(blanks, line)
+			continue
+		}
+		if len(blanks) >= 3 {
+			blanks = blanks[:1]
+		}
+		out = append(out, blanks...)
+		blanks = blanks[:0]
+		if isCodeFence(line) {
+			inFence = !inFence
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// isCodeFence reports whether line opens or closes a fenced code block.
+func isCodeFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// hasCodeFence reports whether any line of s opens a fenced code block.
+func hasCodeFence(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if isCodeFence(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// changelogRangeOptions holds the flags accepted by the changelog command.
+type changelogRangeOptions struct {
+	from string // oldest version to include
+	to   string // newest version to include
+}
+
+// parseChangelogFlags parses the arguments following "changelog".
+func parseChangelogFlags(args []string) (changelogRangeOptions, error) {
+	var opts changelogRangeOptions
+	fs := newFlagSet("changelog")
+	fs.StringVar(&opts.from, "from", "", "oldest `version` to include")
+	fs.StringVar(&opts.to, "to", "", "newest `version` to include")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	if opts.from == "" || opts.to == "" {
+		return opts, fmt.Errorf("both --from and --to are required")
+	}
+	return opts, nil
+}
+
+// cmdChangelog prints the CHANGELOG.md sections from one version to another.
+func cmdChangelog(p paths, opts changelogRangeOptions) error {
+	content, err := readChangelogHistory(filepath.Join(p.root, changelogFile))
+	if err != nil {
+		return fmt.Errorf("failed to read CHANGELOG.md: %w", err)
+	}
+
+	notes, err := changelogRange(content, opts.from, opts.to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(notes)
+	return nil
+}
+
+// changelogRange concatenates the sections from version from to version to,
+// both inclusive, newest first as they appear in the changelog.
+func changelogRange(content, from, to string) (string, error) {
+	sections := parseChangelogSections(content)
+
+	findIndex := func(ver string) (int, error) {
+		for i, s := range sections {
+			if s.version == ver || s.version == "v"+ver {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("version %s not found in CHANGELOG.md", ver)
+	}
+
+	fromIdx, err := findIndex(from)
+	if err != nil {
+		return "", err
+	}
+	toIdx, err := findIndex(to)
+	if err != nil {
+		return "", err
+	}
+
+	// Sections are newest first, so the newer "to" must not come after "from".
+	if toIdx > fromIdx {
+		return "", fmt.Errorf("invalid range: %s is newer than %s", from, to)
+	}
+
+	parts := make([]string, 0, fromIdx-toIdx+1)
+	for _, s := range sections[toIdx : fromIdx+1] {
+		parts = append(parts, strings.TrimRight(content[s.start:s.end], "\n")+"\n")
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+const (
+	// patchOnlyWarnReleases and patchOnlyWarnDays are how many releases, or
+	// days, may pass since the last minor or major release before a patch-only
+	// release is flagged.
+	patchOnlyWarnReleases = 5
+	patchOnlyWarnDays     = 90
+)
+
+// featureGap reports how many releases and days have passed since the most
+// recent minor or major release in the changelog, judged by a zero patch
+// number. found is false when the history has no such release.
+func featureGap(content string, now time.Time) (releases, days int, found bool) {
+	for i, s := range parseChangelogSections(content) {
+		ver, err := semver.NewVersion(strings.TrimPrefix(s.version, "v"))
+		if err != nil || ver.Patch() != 0 || ver.Prerelease() != "" {
+			continue
+		}
+
+		if date, err := time.Parse(releaseDateLayout, s.date); err == nil {
+			days = int(now.Sub(date).Hours() / 24)
+		}
+		return i, days, true
+	}
+	return 0, 0, false
+}
+
+// printPatchOnlyWarning nudges toward communicating features when a patch-only
+// release follows a long run of patch releases or a long quiet period.
+func printPatchOnlyWarning(w io.Writer, changelogPath string, now time.Time) {
+	content, err := readChangelogHistory(changelogPath)
+	if err != nil {
+		return
+	}
+
+	releases, days, found := featureGap(content, now)
+	if !found || (releases < patchOnlyWarnReleases && days < patchOnlyWarnDays) {
+		return
+	}
+	fmt.Fprintf(w, "warning: only patch changes pending, and the last minor or major release was %d releases and %d days ago; are new features being recorded as patches?\n", releases, days)
+}
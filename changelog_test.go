@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareLink(t *testing.T) {
+	tests := []struct {
+		repoURL  string
+		prev     string
+		next     string
+		expected string
+	}{
+		{"https://github.com/o/r", "v1.0.0", "v1.1.0", "[v1.1.0]: https://github.com/o/r/compare/v1.0.0...v1.1.0"},
+		{"https://github.com/o/r/", "v1.0.0", "v1.1.0", "[v1.1.0]: https://github.com/o/r/compare/v1.0.0...v1.1.0"},
+		{"https://github.com/o/r.git", "v1.0.0", "v1.1.0", "[v1.1.0]: https://github.com/o/r/compare/v1.0.0...v1.1.0"},
+		{"", "v1.0.0", "v1.1.0", ""},
+		{"https://github.com/o/r", "", "v1.1.0", ""},
+	}
+
+	for _, tt := range tests {
+		if got := compareLink(tt.repoURL, tt.prev, tt.next); got != tt.expected {
+			t.Errorf("compareLink(%q, %q, %q) = %q, expected %q", tt.repoURL, tt.prev, tt.next, got, tt.expected)
+		}
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	if got := compareURL("https://github.com/o/r.git", "v1.0.0", "v1.1.0"); got != "https://github.com/o/r/compare/v1.0.0...v1.1.0" {
+		t.Errorf("unexpected compare URL %q", got)
+	}
+	if got := compareURL("https://github.com/o/r", "", "v1.1.0"); got != "" {
+		t.Errorf("expected no URL without a previous version, got %q", got)
+	}
+}
+
+func TestInsertCompareLinkNewBlock(t *testing.T) {
+	content := "# Changelog\n\n## [v1.1.0] - 2026-01-01\n\n- Fix\n"
+	link := "[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0"
+
+	got := insertCompareLink(content, link)
+
+	expected := "# Changelog\n\n## [v1.1.0] - 2026-01-01\n\n- Fix\n\n" + link + "\n"
+	if got != expected {
+		t.Errorf("unexpected content.\nExpected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestInsertCompareLinkExistingBlock(t *testing.T) {
+	old := "[v1.0.0]: https://example.com/compare/v0.9.0...v1.0.0"
+	content := "# Changelog\n\n## [v1.1.0] - 2026-01-01\n\n- Fix\n\n## [v1.0.0] - 2025-01-01\n\n- Old\n\n" + old + "\n"
+	link := "[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0"
+
+	got := insertCompareLink(content, link)
+
+	if !strings.HasSuffix(got, link+"\n"+old+"\n") {
+		t.Errorf("expected new link before the existing one, got:\n%s", got)
+	}
+	if strings.Count(got, "\n\n[") != 1 {
+		t.Errorf("expected a single link block, got:\n%s", got)
+	}
+}
+
+func TestInsertCompareLinkReplacesSameLabel(t *testing.T) {
+	old := "[v1.1.0]: https://example.com/compare/v0.9.0...v1.1.0"
+	content := "# Changelog\n\n## [v1.1.0] - 2026-01-01\n\n- Fix\n\n" + old + "\n"
+	link := "[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0"
+
+	got := insertCompareLink(content, link)
+
+	if strings.Contains(got, old) || strings.Count(got, "[v1.1.0]:") != 1 {
+		t.Errorf("expected the existing definition to be replaced, got:\n%s", got)
+	}
+}
+
+func TestFindChangelogSection(t *testing.T) {
+	content := "# Changelog\n\n## v1.1.0 - 2026-01-01\n\n- Fix\n\n## v1.0.0 - 2025-01-01\n\n- Old\n"
+
+	s, ok := findChangelogSection(content, "## [v1.0.0] - 2026-03-01\n\n- New\n")
+	if !ok {
+		t.Fatal("expected v1.0.0 section to be found")
+	}
+	if !strings.HasPrefix(content[s.start:], "## v1.0.0") {
+		t.Errorf("unexpected section start %d", s.start)
+	}
+
+	if _, ok := findChangelogSection(content, "## v2.0.0 - 2026-03-01\n"); ok {
+		t.Error("expected v2.0.0 not to be found")
+	}
+	if _, ok := findChangelogSection(content, "no header"); ok {
+		t.Error("expected section without header not to match")
+	}
+}
+
+func TestAddCompareLink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	os.WriteFile(path, []byte("# Changelog\n\n## [v1.1.0] - 2026-01-01\n"), 0644)
+
+	link := "[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0"
+	if err := addCompareLink(path, link); err != nil {
+		t.Fatalf("addCompareLink failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), link) {
+		t.Errorf("expected link in changelog, got:\n%s", data)
+	}
+}
+
+func TestAddCompareLinkMissingFile(t *testing.T) {
+	if err := addCompareLink("/nonexistent/CHANGELOG.md", "[v1]: x"); err == nil {
+		t.Fatal("expected error for missing changelog")
+	}
+}
+
+func TestParseChangelogSections(t *testing.T) {
+	content := "# Changelog\n\n## v1.1.0 - 2026-02-01\n\n### Minor Changes\n\n- Feature\n\n## [v1.0.0] - 2026-01-01\n\n- Initial\n"
+
+	sections := parseChangelogSections(content)
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+
+	if sections[0].version != "v1.1.0" || sections[0].date != "2026-02-01" {
+		t.Errorf("unexpected first section: %+v", sections[0])
+	}
+	if sections[1].version != "v1.0.0" || sections[1].date != "2026-01-01" {
+		t.Errorf("unexpected second section: %+v", sections[1])
+	}
+
+	first := content[sections[0].start:sections[0].end]
+	if !strings.HasPrefix(first, "## v1.1.0") || !strings.HasSuffix(first, "- Feature\n\n") {
+		t.Errorf("unexpected first section body: %q", first)
+	}
+	second := content[sections[1].start:sections[1].end]
+	if second != "## [v1.0.0] - 2026-01-01\n\n- Initial\n" {
+		t.Errorf("unexpected second section body: %q", second)
+	}
+}
+
+func TestParseChangelogSectionsStopsAtLinks(t *testing.T) {
+	content := "# Changelog\n\n## [v1.0.0] - 2026-01-01\n\n- Initial\n\n[v1.0.0]: https://example.com/compare/v0.9.0...v1.0.0\n"
+
+	sections := parseChangelogSections(content)
+	if len(sections) != 1 {
+		t.Fatalf("expected 1 section, got %d", len(sections))
+	}
+	body := content[sections[0].start:sections[0].end]
+	if strings.Contains(body, "https://") {
+		t.Errorf("section should not include the link block: %q", body)
+	}
+}
+
+func TestArchiveChangelogSections(t *testing.T) {
+	content := "# Changelog\n\n## [v1.2.0]\n\n- Third\n\n## [v1.1.0]\n\n- Second\n\n## v1.0.0\n\n- First\n\n" +
+		"[v1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n"
+
+	kept, archive, err := archiveChangelogSections(content, "", "CHANGELOG.archive.md", 1)
+	if err != nil {
+		t.Fatalf("archiveChangelogSections failed: %v", err)
+	}
+	if want := "# Changelog\n\n## [v1.2.0]\n\n- Third\n\n[v1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n"; kept != want {
+		t.Errorf("unexpected changelog:\n%q\nexpected:\n%q", kept, want)
+	}
+	if want := "# Changelog Archive\n\n## [v1.1.0]\n\n- Second\n\n## v1.0.0\n\n- First\n\n[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n"; archive != want {
+		t.Errorf("unexpected archive:\n%q\nexpected:\n%q", archive, want)
+	}
+
+	// Sections archived later go above the ones already there, and so do their links.
+	content = "# Changelog\n\n## [v1.3.0]\n\n- Fourth\n\n## [v1.2.0]\n\n- Third\n\n" +
+		"[v1.3.0]: https://example.com/compare/v1.2.0...v1.3.0\n[v1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n"
+	_, archive, err = archiveChangelogSections(content, archive, "CHANGELOG.archive.md", 1)
+	if err != nil {
+		t.Fatalf("archiveChangelogSections failed: %v", err)
+	}
+	want := "# Changelog Archive\n\n## [v1.2.0]\n\n- Third\n\n## [v1.1.0]\n\n- Second\n\n## v1.0.0\n\n- First\n\n" +
+		"[v1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n"
+	if archive != want {
+		t.Errorf("unexpected archive:\n%q\nexpected:\n%q", archive, want)
+	}
+
+	for _, max := range []int{0, 3} {
+		if kept, archive, _ := archiveChangelogSections(content, "", "CHANGELOG.archive.md", max); kept != content || archive != "" {
+			t.Errorf("expected max %d to keep every section, got %q and %q", max, kept, archive)
+		}
+	}
+}
+
+func TestReadChangelogHistory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, changelogFile)
+	os.WriteFile(path, []byte("# Changelog\n\n## [v1.2.0] - 2026-03-01\n\n- Third\n\n[v1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n"), 0644)
+
+	content, err := readChangelogHistory(path)
+	if err != nil || !strings.HasSuffix(content, "v1.2.0\n") {
+		t.Fatalf("expected the changelog alone without an archive, got %q (%v)", content, err)
+	}
+
+	os.WriteFile(changelogArchivePath(path), []byte("# Changelog Archive\n\n## [v1.1.0] - 2026-02-01\n\n- Second\n\n[v1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n"), 0644)
+	content, err = readChangelogHistory(path)
+	if err != nil {
+		t.Fatalf("readChangelogHistory failed: %v", err)
+	}
+	sections := parseChangelogSections(content)
+	if len(sections) != 2 || sections[0].version != "v1.2.0" || sections[1].version != "v1.1.0" {
+		t.Fatalf("expected the archived section after the current one, got %+v", sections)
+	}
+	if body := content[sections[0].start:sections[0].end]; strings.Contains(body, "https://") || strings.Contains(body, "Archive") {
+		t.Errorf("links and the archive heading should not end up in a section: %q", body)
+	}
+	if notes, err := changelogRange(content, "v1.1.0", "v1.2.0"); err != nil || !strings.Contains(notes, "- Second") {
+		t.Errorf("expected the range to reach into the archive, got %q (%v)", notes, err)
+	}
+
+	if _, err := readChangelogHistory(filepath.Join(dir, "missing.md")); err == nil {
+		t.Error("expected error for a missing changelog")
+	}
+}
+
+func TestChangelogArchivePath(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"CHANGELOG.md", "CHANGELOG.archive.md"},
+		{"/repo/api/CHANGELOG-beta.md", "/repo/api/CHANGELOG-beta.archive.md"},
+	}
+	for _, tt := range tests {
+		if got := changelogArchivePath(tt.path); got != tt.want {
+			t.Errorf("changelogArchivePath(%q) = %q, expected %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseChangelogSectionsSkipsOtherHeadings(t *testing.T) {
+	content := "# Changelog\n\n## How to read this changelog\n\nNewest first.\n\n## Unreleased\n\n## v2024.3.0 - 2024-03-01\n\n- Calver\n\n## [1.0.0-rc.1] - 2023-12-01 (rc)\n\n- Candidate\n"
+
+	sections := parseChangelogSections(content)
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %+v", sections)
+	}
+	if sections[0].version != "v2024.3.0" || sections[1].version != "1.0.0-rc.1" || sections[1].date != "2023-12-01" {
+		t.Errorf("unexpected sections %+v", sections)
+	}
+	if !strings.HasPrefix(content[sections[0].start:], "## v2024.3.0") {
+		t.Errorf("expected the first section to start at the calver header, got %q", content[sections[0].start:])
+	}
+}
+
+func TestParseChangelogSectionsAfterMarker(t *testing.T) {
+	content := "# Changelog\n\n## v9.9.9 example of a release header\n\n" + changelogInsertMarker + "\n\n## v1.0.0 - 2026-01-01\n\n- Initial\n"
+
+	sections := parseChangelogSections(content)
+	if len(sections) != 1 || sections[0].version != "v1.0.0" {
+		t.Fatalf("expected only the section below the marker, got %+v", sections)
+	}
+	if sections := parseChangelogSections("# Changelog\n\n## v1.0.0\n\n" + changelogInsertMarker); len(sections) != 0 {
+		t.Errorf("expected nothing below a trailing marker, got %+v", sections)
+	}
+}
+
+func TestParseChangelogSectionsNoDate(t *testing.T) {
+	sections := parseChangelogSections("## v1.0.0\n- Fix")
+	if len(sections) != 1 {
+		t.Fatalf("expected 1 section, got %d", len(sections))
+	}
+	if sections[0].version != "v1.0.0" || sections[0].date != "" {
+		t.Errorf("unexpected section: %+v", sections[0])
+	}
+	if sections[0].end != len("## v1.0.0\n- Fix") {
+		t.Errorf("expected section to run to the end, got end %d", sections[0].end)
+	}
+}
+
+func TestParseChangelogSectionsEmpty(t *testing.T) {
+	if sections := parseChangelogSections("# Changelog\n"); len(sections) != 0 {
+		t.Errorf("expected no sections, got %d", len(sections))
+	}
+	if sections := parseChangelogSections(""); len(sections) != 0 {
+		t.Errorf("expected no sections for empty content, got %d", len(sections))
+	}
+}
+
+func TestLinkBlockStart(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected int
+	}{
+		{"# Changelog\n", len("# Changelog\n")},
+		{"text\n\n[a]: https://a\n[b]: https://b\n", len("text\n\n")},
+		{"[a]: https://a\n", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := linkBlockStart(tt.content); got != tt.expected {
+			t.Errorf("linkBlockStart(%q) = %d, expected %d", tt.content, got, tt.expected)
+		}
+	}
+}
+
+func TestChangelogRange(t *testing.T) {
+	content := "# Changelog\n\n## v1.3.0 - 2026-03-01\n\n- C\n\n## v1.2.0 - 2026-02-01\n\n- B\n\n## v1.1.0 - 2026-01-15\n\n- A\n\n## v1.0.0 - 2026-01-01\n\n- Initial\n\n[v1.3.0]: https://example.com/compare/v1.2.0...v1.3.0\n"
+
+	got, err := changelogRange(content, "v1.1.0", "1.2.0")
+	if err != nil {
+		t.Fatalf("changelogRange failed: %v", err)
+	}
+	expected := "## v1.2.0 - 2026-02-01\n\n- B\n\n## v1.1.0 - 2026-01-15\n\n- A\n"
+	if got != expected {
+		t.Errorf("unexpected range.\nExpected:\n%q\nGot:\n%q", expected, got)
+	}
+
+	got, _ = changelogRange(content, "v1.3.0", "v1.3.0")
+	if got != "## v1.3.0 - 2026-03-01\n\n- C\n" {
+		t.Errorf("expected the single v1.3.0 section without links, got %q", got)
+	}
+}
+
+func TestChangelogRangeErrors(t *testing.T) {
+	content := "# Changelog\n\n## v1.1.0 - 2026-01-15\n\n- A\n\n## v1.0.0 - 2026-01-01\n\n- Initial\n"
+
+	if _, err := changelogRange(content, "v0.9.0", "v1.1.0"); err == nil {
+		t.Error("expected error for unknown --from version")
+	}
+	if _, err := changelogRange(content, "v1.0.0", "v2.0.0"); err == nil {
+		t.Error("expected error for unknown --to version")
+	}
+	if _, err := changelogRange(content, "v1.1.0", "v1.0.0"); err == nil {
+		t.Error("expected error for inverted range")
+	}
+}
+
+func TestParseChangelogFlags(t *testing.T) {
+	opts, err := parseChangelogFlags([]string{"--from", "v1.0.0", "--to", "v1.3.0"})
+	if err != nil {
+		t.Fatalf("parseChangelogFlags failed: %v", err)
+	}
+	if opts.from != "v1.0.0" || opts.to != "v1.3.0" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if _, err := parseChangelogFlags([]string{"--from", "v1.0.0"}); err == nil {
+		t.Error("expected error without --to")
+	}
+}
+
+func TestCmdChangelog(t *testing.T) {
+	p := setupProject(t, "v1.1.0")
+	os.WriteFile(filepath.Join(p.root, changelogFile), []byte("# Changelog\n\n## v1.1.0 - 2026-01-15\n\n- A\n\n## v1.0.0 - 2026-01-01\n\n- Initial\n"), 0644)
+
+	output := captureStdout(func() {
+		if err := cmdChangelog(p, changelogRangeOptions{from: "v1.0.0", to: "v1.1.0"}); err != nil {
+			t.Fatalf("cmdChangelog failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "- A") || !strings.Contains(output, "- Initial") {
+		t.Errorf("expected both sections, got:\n%s", output)
+	}
+
+	if err := cmdChangelog(newPaths(t.TempDir()), changelogRangeOptions{from: "v1.0.0", to: "v1.1.0"}); err == nil {
+		t.Error("expected error without CHANGELOG.md")
+	}
+}
+
+func TestFeatureGap(t *testing.T) {
+	content := "# Changelog\n\n## v1.2.2 - 2026-03-01\n\n- C\n\n## v1.2.1 - 2026-02-01\n\n- B\n\n## v1.2.0 - 2026-01-01\n\n- A\n"
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	releases, days, found := featureGap(content, now)
+	if !found {
+		t.Fatal("expected v1.2.0 to be found")
+	}
+	if releases != 2 || days != 60 {
+		t.Errorf("expected 2 releases and 60 days, got %d and %d", releases, days)
+	}
+
+	if _, _, found := featureGap("# Changelog\n\n## v0.0.1 - 2026-01-01\n", now); found {
+		t.Error("expected no minor or major release in patch-only history")
+	}
+}
+
+func TestPrintPatchOnlyWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	os.WriteFile(path, []byte("# Changelog\n\n## v1.2.1 - 2026-02-01\n\n- B\n\n## v1.2.0 - 2026-01-01\n\n- A\n"), 0644)
+
+	var buf bytes.Buffer
+	printPatchOnlyWarning(&buf, path, time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC))
+	if buf.Len() != 0 {
+		t.Errorf("expected no warning for a recent feature release, got %q", buf.String())
+	}
+
+	printPatchOnlyWarning(&buf, path, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	if !strings.Contains(buf.String(), "1 releases and 151 days ago") {
+		t.Errorf("expected a warning after a long gap, got %q", buf.String())
+	}
+
+	buf.Reset()
+	printPatchOnlyWarning(&buf, filepath.Join(t.TempDir(), "missing.md"), time.Now())
+	if buf.Len() != 0 {
+		t.Errorf("expected no warning without a changelog, got %q", buf.String())
+	}
+}
+
+func TestNormalizeChangelog(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"# Changelog\n\n\n\n## v1.0.0\n\n- Fix\n\n\n", "# Changelog\n\n## v1.0.0\n\n- Fix\n"},
+		{"# Changelog", "# Changelog\n"},
+		{"## v1.0.0\n\n  \n\n- Fix\n", "## v1.0.0\n\n- Fix\n"},
+		{"- Fix\n\n  ```\n  a\n\n\n  b\n  ```\n\n\n", "- Fix\n\n  ```\n  a\n\n\n  b\n  ```\n"},
+		{"# Changelog\n\nIntro.\n\n\nMore.\n", "# Changelog\n\nIntro.\n\n\nMore.\n"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeChangelog(tt.content); got != tt.want {
+			t.Errorf("normalizeChangelog(%q) = %q, expected %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestSequentialReleasesSpacing(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	saveConfig(p.config, &config{Version: "v1.0.0", RepoURL: "https://github.com/o/r", CompareLinks: true})
+	path := filepath.Join(p.root, changelogFile)
+	os.WriteFile(path, []byte("# Changelog\n\n\n"), 0644)
+
+	for i, bump := range []string{"patch", "minor", "patch", "major"} {
+		os.WriteFile(filepath.Join(p.changes, "change.md"), []byte("---\ntest: "+bump+"\n---\n\nChange "+bump+"\n\n"), 0644)
+		var err error
+		captureStdout(func() {
+			err = cmdRelease(p, newScanner(""), releaseOptions{})
+		})
+		if err != nil {
+			t.Fatalf("release %d failed: %v", i, err)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if strings.Contains(content, "\n\n\n") {
+		t.Errorf("expected no double blank lines, got:\n%s", content)
+	}
+	if !strings.HasSuffix(content, "\n") || strings.HasSuffix(content, "\n\n") {
+		t.Errorf("expected exactly one trailing newline, got %q", content[len(content)-10:])
+	}
+	if n := strings.Count(content, "\n## "); n != 4 {
+		t.Errorf("expected 4 release sections, got %d:\n%s", n, content)
+	}
+}
+
+func TestRenderSummary(t *testing.T) {
+	summary := "Reworked the parser\n\nIt is faster\nand stricter."
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"", "Reworked the parser"},
+		{summaryRenderFirstLine, "Reworked the parser"},
+		{summaryRenderCollapse, "Reworked the parser It is faster and stricter."},
+		{summaryRenderIndent, "Reworked the parser\n\n  It is faster\n  and stricter."},
+	}
+
+	for _, tt := range tests {
+		if got := renderSummary(summary, tt.mode); got != tt.want {
+			t.Errorf("renderSummary(%q) = %q, expected %q", tt.mode, got, tt.want)
+		}
+	}
+	if got := renderSummary("One line", summaryRenderIndent); got != "One line" {
+		t.Errorf("expected a single line to be unchanged, got %q", got)
+	}
+}
+
+func TestRenderSummaryFencedBody(t *testing.T) {
+	summary := "Add config:\n\n```go\ncfg := New()\n```"
+	want := "Add config:\n\n  ```go\n  cfg := New()\n  ```"
+	for _, mode := range []string{"", summaryRenderFirstLine, summaryRenderIndent} {
+		if got := renderSummary(summary, mode); got != want {
+			t.Errorf("renderSummary(%q) = %q, expected the code block kept as %q", mode, got, want)
+		}
+	}
+
+	changes := []*changeset{{filepath: "a.md", bump: patch, summary: summary}}
+	opts := changelogOptions{
+		date:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		commitSHA: func(string) (string, error) { return "", nil },
+	}
+	result, err := buildChangelogSection("v1.0.1", changes, opts)
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "- Add config:\n\n  ```go\n  cfg := New()\n  ```\n") {
+		t.Errorf("expected the code block under the entry, got %q", result)
+	}
+}
+
+func TestFindDroppedSummaries(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/c/one.md", summary: "One line"},
+		{filepath: "/c/two.md", summary: "Reworked the parser\n\nIt is faster."},
+		{filepath: "/c/code.md", summary: "Add config:\n\n```go\ncfg := New()\n```"},
+		{filepath: "/c/list.md", summary: "- First\n- Second"},
+	}
+
+	dropped := findDroppedSummaries(changes, "")
+	if len(dropped) != 1 || !strings.HasPrefix(dropped[0].Error(), "two.md: summary lines after the first") {
+		t.Errorf("expected only two.md to lose lines, got %v", dropped)
+	}
+	if dropped := findDroppedSummaries(changes, summaryRenderIndent); len(dropped) != 0 {
+		t.Errorf("expected nothing dropped with indent, got %v", dropped)
+	}
+}
+
+func TestBuildChangelogSectionSummaryRender(t *testing.T) {
+	changes := []*changeset{{filepath: "a.md", bump: patch, summary: "Fixed a crash\n\nThe config is now checked first."}}
+	opts := changelogOptions{
+		date:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		commitSHA: func(string) (string, error) { return "", nil },
+	}
+
+	result, err := buildChangelogSection("v1.0.1", changes, opts)
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "### Patch Changes\n\n- Fixed a crash\n") {
+		t.Errorf("expected only the first line by default, got %q", result)
+	}
+
+	opts.summaryRender = summaryRenderIndent
+	result, _ = buildChangelogSection("v1.0.1", changes, opts)
+	if !strings.HasSuffix(result, "- Fixed a crash\n\n  The config is now checked first.\n") {
+		t.Errorf("expected the rest indented under the entry, got %q", result)
+	}
+}
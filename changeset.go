@@ -1,10 +1,16 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 )
 
 // bumpType represents a semantic version bump level.
@@ -14,14 +20,46 @@ const (
 	patch bumpType = "patch"
 	minor bumpType = "minor"
 	major bumpType = "major"
+
+	// none records a changelog note without changing the version.
+	none bumpType = "none"
 )
 
 // changeset represents a parsed changeset file.
 type changeset struct {
-	filepath string   // absolute path to the .md file
-	repoName string   // repo name from frontmatter
-	bump     bumpType // patch, minor, or major
-	summary  string   // the message body
+	filepath         string    // absolute path to the .md file
+	slug             string    // filename without the .md extension
+	repoName         string    // first package name from frontmatter
+	bump             bumpType  // highest bump across all packages
+	releases         []release // every "name: bump" line from frontmatter, in order
+	author           string    // optional "author:" frontmatter field crediting the change
+	collapse         string    // optional "collapse:" label grouping the change into one changelog line
+	summary          string    // the message body
+	details          string    // optional details block preceding the summary
+	detailsDelimiter string    // delimiter that fenced details, used when rewriting the file
+}
+
+// Reserved frontmatter keys that are not package names.
+const (
+	authorKey   = "author"   // who to credit for the change
+	collapseKey = "collapse" // changelog line the change is counted under instead of listed
+)
+
+// release is a single package bump declared in a changeset's frontmatter.
+type release struct {
+	name string
+	bump bumpType
+}
+
+// parseOptions controls how changeset files are read and parsed.
+type parseOptions struct {
+	maxSize     int64     // maximum file size in bytes; zero or less means unlimited
+	lenientBump bool      // treat unknown bump types as patch instead of failing
+	warnings    io.Writer // where lenient-mode warnings are written; nil discards them
+
+	// detailsDelimiter, when set, marks a details block at the start of the
+	// body, fenced by lines holding exactly this delimiter.
+	detailsDelimiter string
 }
 
 // parseFile reads and parses a changeset markdown file.
@@ -32,18 +70,53 @@ type changeset struct {
 //	---
 //
 //	Summary text here
-func parseFile(path string) (*changeset, error) {
-	data, err := os.ReadFile(path)
+//
+// The blank line after the closing delimiter is optional; content always
+// writes it.
+//
+// In a monorepo the frontmatter may list several packages, one per line. An
+// optional "author: @name" line credits someone other than the commit author,
+// and "collapse: Dependency updates" counts the change under that single
+// changelog line instead of listing it.
+//
+// At most opts.maxSize bytes are read, so an accidentally huge file is rejected
+// without being loaded into memory.
+func parseFile(path string, opts parseOptions) (*changeset, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read changeset %s: %w", path, err)
 	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if opts.maxSize > 0 {
+		r = io.LimitReader(f, opts.maxSize+1)
+	}
 
-	return parseChangeset(string(data), path)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changeset %s: %w", path, err)
+	}
+	if opts.maxSize > 0 && int64(len(data)) > opts.maxSize {
+		return nil, fmt.Errorf("changeset %s %w of %d bytes", path, errChangesetTooLarge, opts.maxSize)
+	}
+
+	return parseChangeset(string(data), path, opts)
 }
 
+// errChangesetTooLarge and errChangesetEmpty let prune tell a changeset that
+// is merely over the size limit from one with nothing in it.
+var (
+	errChangesetTooLarge = errors.New("exceeds the maximum size")
+	errChangesetEmpty    = errors.New("changeset is empty")
+)
+
 // parseChangeset parses changeset content from a string.
-func parseChangeset(content, filePath string) (*changeset, error) {
+func parseChangeset(content, filePath string, opts parseOptions) (*changeset, error) {
 	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errChangesetEmpty
+	}
 
 	if !strings.HasPrefix(content, "---") {
 		return nil, fmt.Errorf("changeset missing opening frontmatter delimiter (---)")
@@ -58,67 +131,332 @@ func parseChangeset(content, filePath string) (*changeset, error) {
 	}
 
 	frontmatter := strings.TrimSpace(rest[:idx])
-	body := strings.TrimSpace(rest[idx+4:])
+	body := trimBlankLines(rest[idx+4:])
 
-	// Parse frontmatter: "repo-name: bump-type"
-	parts := strings.SplitN(frontmatter, ":", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid frontmatter format, expected 'name: bump-type'")
+	var details string
+	if opts.detailsDelimiter != "" {
+		var err error
+		if details, body, err = splitDetails(body, opts.detailsDelimiter); err != nil {
+			return nil, err
+		}
 	}
 
-	repoName := strings.TrimSpace(parts[0])
-	bumpStr := strings.TrimSpace(parts[1])
+	// Parse frontmatter: one "name: bump-type" line per package
+	var releases []release
+	var author, collapse string
+	for _, line := range strings.Split(frontmatter, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
 
-	b, err := parseBumpType(bumpStr)
-	if err != nil {
-		return nil, err
-	}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid frontmatter format, expected 'name: bump-type'")
+		}
 
-	return &changeset{
+		key := strings.TrimSpace(parts[0])
+		value := unquote(strings.TrimSpace(parts[1]))
+		switch key {
+		case authorKey:
+			author = value
+			continue
+		case collapseKey:
+			collapse = value
+			continue
+		}
+
+		b, err := parseBumpType(value)
+		if err != nil {
+			if !opts.lenientBump {
+				return nil, err
+			}
+			if opts.warnings != nil {
+				fmt.Fprintf(opts.warnings, "warning: %s: unknown bump type %q for %s, treating as patch\n", filepath.Base(filePath), value, key)
+			}
+			b = patch
+		}
+		releases = append(releases, release{name: key, bump: b})
+	}
+	cs := &changeset{
 		filepath: filePath,
-		repoName: repoName,
-		bump:     b,
+		slug:     filenameToSlug(filepath.Base(filePath)),
+		releases: releases,
+		author:   author,
+		collapse: collapse,
 		summary:  body,
-	}, nil
+		details:  details,
+	}
+	if details != "" {
+		cs.detailsDelimiter = opts.detailsDelimiter
+	}
+	if err := cs.Validate(); err != nil {
+		return nil, err
+	}
+
+	cs.repoName = releases[0].name
+	cs.bump = none
+	for _, r := range releases {
+		if bumpPriority(r.bump) > bumpPriority(cs.bump) {
+			cs.bump = r.bump
+		}
+	}
+
+	return cs, nil
+}
+
+// Validate checks the rules every changeset must satisfy: at least one
+// package, each with a name and a known bump type, and a non-empty summary.
+func (cs *changeset) Validate() error {
+	if len(cs.releases) == 0 {
+		return fmt.Errorf("invalid frontmatter format, expected 'name: bump-type'")
+	}
+	for _, r := range cs.releases {
+		if r.name == "" {
+			return fmt.Errorf("package name cannot be empty")
+		}
+		if _, err := parseBumpType(string(r.bump)); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(cs.summary) == "" {
+		return fmt.Errorf("summary cannot be empty")
+	}
+	return nil
+}
+
+// splitDetails separates a details block fenced by delim from the rest of the
+// body. A body that does not start with delim has no details.
+func splitDetails(body, delim string) (details, summary string, err error) {
+	first, rest, _ := strings.Cut(body, "\n")
+	if strings.TrimSpace(first) != delim {
+		return "", body, nil
+	}
+
+	lines := strings.Split(rest, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == delim {
+			details = trimBlankLines(strings.Join(lines[:i], "\n"))
+			summary = trimBlankLines(strings.Join(lines[i+1:], "\n"))
+			return details, summary, nil
+		}
+	}
+	return "", "", fmt.Errorf("changeset missing closing details delimiter (%s)", delim)
+}
+
+// trimBlankLines removes the blank lines before and after s, along with
+// trailing whitespace on its last line. Unlike strings.TrimSpace it keeps the
+// indentation of the first line, so an indented code block or a nested list
+// at the start of a summary survives.
+func trimBlankLines(s string) string {
+	lines := strings.Split(strings.TrimRight(s, " \t\r\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// bulletPrefix starts a changelog entry in a summary written as a bullet list.
+const bulletPrefix = "- "
+
+// summaryEntries splits a summary into the changelog entries it produces. A
+// summary whose first line starts with "- " is a bullet list and becomes one
+// entry per bullet. Lines that do not start a bullet continue the previous
+// one, less the two spaces that align them with its text, so nested lists keep
+// their nesting; blank lines between bullets are dropped. Any other summary is
+// a single entry, as written.
+func summaryEntries(summary string) []string {
+	if !strings.HasPrefix(summary, bulletPrefix) {
+		return []string{summary}
+	}
+
+	var entries []string
+	for _, line := range strings.Split(summary, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+		case strings.HasPrefix(line, bulletPrefix):
+			entries = append(entries, strings.TrimSpace(strings.TrimPrefix(line, bulletPrefix)))
+		default:
+			entries[len(entries)-1] += "\n" + strings.TrimPrefix(line, "  ")
+		}
+	}
+	return entries
 }
 
-// changesetContent produces the markdown content for a changeset file.
+// unquote strips one pair of matching single or double quotes around s, so
+// YAML-style values such as "minor" or 'minor' are accepted.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// changesetContent produces the markdown content for a single-package changeset file.
 func changesetContent(repoName string, bump bumpType, summary string) string {
-	return fmt.Sprintf("---\n%s: %s\n---\n\n%s\n", repoName, bump, summary)
+	cs := &changeset{releases: []release{{name: repoName, bump: bump}}, summary: summary}
+	return cs.content()
 }
 
-// listChangesets reads all .md files in the changes directory and parses them.
-func listChangesets(changesDir string) ([]*changeset, error) {
+// content renders the changeset back to its markdown file format.
+func (cs *changeset) content() string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, r := range cs.releases {
+		fmt.Fprintf(&sb, "%s: %s\n", r.name, r.bump)
+	}
+	if cs.author != "" {
+		fmt.Fprintf(&sb, "%s: %s\n", authorKey, cs.author)
+	}
+	if cs.collapse != "" {
+		fmt.Fprintf(&sb, "%s: %s\n", collapseKey, cs.collapse)
+	}
+	sb.WriteString("---\n\n")
+	if cs.details != "" {
+		fmt.Fprintf(&sb, "%s\n%s\n%s\n\n", cs.detailsDelimiter, cs.details, cs.detailsDelimiter)
+	}
+	fmt.Fprintf(&sb, "%s\n", cs.summary)
+	return sb.String()
+}
+
+// contentHash returns a SHA-256 hash of the changeset's canonical content, so
+// changesets that differ only in formatting hash the same.
+func (cs *changeset) contentHash() string {
+	canonical := *cs
+	canonical.summary = strings.TrimSpace(cs.summary)
+	canonical.details = strings.TrimSpace(cs.details)
+	sum := sha256.Sum256([]byte(canonical.content()))
+	return hex.EncodeToString(sum[:])
+}
+
+// findDuplicate returns the first changeset in changes with the same content
+// as cs, or nil if there is none.
+func findDuplicate(changes []*changeset, cs *changeset) *changeset {
+	hash := cs.contentHash()
+	for _, other := range changes {
+		if other.contentHash() == hash {
+			return other
+		}
+	}
+	return nil
+}
+
+// bumpFor returns the bump the changeset declares for the named package.
+func (cs *changeset) bumpFor(name string) (bumpType, bool) {
+	for _, r := range cs.releases {
+		if r.name == name {
+			return r.bump, true
+		}
+	}
+	return "", false
+}
+
+// isChangesetFile reports whether a file in the changes directory holds a
+// changeset: any .md file except README.md, which sits alongside the
+// changesets in the flat layout.
+func isChangesetFile(name string) bool {
+	return strings.HasSuffix(name, ".md") && name != readmeFile
+}
+
+// listChangesets reads all changeset files in the changes directory and parses them.
+// It fails on the first invalid changeset.
+func listChangesets(changesDir string, opts parseOptions) ([]*changeset, error) {
+	result, errs, err := collectChangesets(changesDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return result, nil
+}
+
+// collectChangesets parses every .md file in the changes directory, returning
+// the valid changesets and one error per file that failed to parse. err is only
+// set when the directory itself cannot be read.
+func collectChangesets(changesDir string, opts parseOptions) (result []*changeset, errs []error, err error) {
 	entries, err := os.ReadDir(changesDir)
 	if err != nil {
-		return nil, fmt.Errorf("read changes directory: %w", err)
+		return nil, nil, fmt.Errorf("read changes directory: %w", err)
 	}
 
-	var result []*changeset
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		if !strings.HasSuffix(entry.Name(), ".md") {
+		if !isChangesetFile(entry.Name()) {
 			continue
 		}
 
 		path := filepath.Join(changesDir, entry.Name())
-		cs, err := parseFile(path)
+		cs, err := parseFile(path, opts)
 		if err != nil {
-			return nil, fmt.Errorf("parse %s: %w", entry.Name(), err)
+			errs = append(errs, &fileError{file: entry.Name(), op: "parse", err: err})
+			continue
 		}
 
 		result = append(result, cs)
 	}
 
-	return result, nil
+	return result, errs, nil
+}
+
+// fileError is a problem with a single changeset file, such as one that
+// failed to parse. validate uses it to attribute errors to files.
+type fileError struct {
+	file string // base name of the changeset file
+	op   string // what failed, e.g. "parse"; may be empty
+	err  error
+}
+
+func (e *fileError) Error() string {
+	if e.op == "" {
+		return fmt.Sprintf("%s: %v", e.file, e.err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.op, e.file, e.err)
+}
+
+func (e *fileError) Unwrap() error { return e.err }
+
+// nestedChangesets returns the .md files in subdirectories of the changes
+// directory, which listChangesets does not read.
+func nestedChangesets(changesDir string) []string {
+	var nested []string
+	filepath.WalkDir(changesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && isChangesetFile(d.Name()) && filepath.Dir(path) != changesDir {
+			nested = append(nested, path)
+		}
+		return nil
+	})
+	return nested
+}
+
+// noChangesetsError reports that there is nothing to release, pointing out
+// changesets left in subdirectories since those are easy to miss.
+func noChangesetsError(changesDir string) error {
+	nested := nestedChangesets(changesDir)
+	if len(nested) == 0 {
+		return fmt.Errorf("%w, nothing to release", ErrNoChangesets)
+	}
+	rel, err := filepath.Rel(changesDir, nested[0])
+	if err != nil {
+		rel = nested[0]
+	}
+	return fmt.Errorf("%w, nothing to release; %d in subdirectories (e.g. %s) are not read, move them to the top of the changes directory",
+		ErrNoChangesets, len(nested), filepath.ToSlash(rel))
 }
 
 // highestBump returns the highest bump type among changesets.
-// major > minor > patch
+// major > minor > patch > none. Without changesets it is patch.
 func highestBump(changes []*changeset) bumpType {
-	highest := patch
+	if len(changes) == 0 {
+		return patch
+	}
+
+	highest := none
 	for _, cs := range changes {
 		if bumpPriority(cs.bump) > bumpPriority(highest) {
 			highest = cs.bump
@@ -130,11 +468,97 @@ func highestBump(changes []*changeset) bumpType {
 
 func parseBumpType(s string) (bumpType, error) {
 	switch bumpType(s) {
-	case patch, minor, major:
+	case patch, minor, major, none:
 		return bumpType(s), nil
 	default:
-		return "", fmt.Errorf("invalid bump type %q, expected patch, minor, or major", s)
+		return "", fmt.Errorf("invalid bump type %q, expected patch, minor, major, or none", s)
+	}
+}
+
+// readBumpFile reads the default bump type from the .bump file in root. It
+// returns an empty bump type when the file does not exist.
+func readBumpFile(root string) (bumpType, error) {
+	data, err := os.ReadFile(filepath.Join(root, bumpFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", bumpFile, err)
+	}
+
+	b, err := parseBumpType(strings.TrimSpace(string(data)))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", bumpFile, err)
+	}
+	return b, nil
+}
+
+// defaultBumpKeywords are the summary keywords add --bump-from-body looks for
+// when the config does not define its own.
+var defaultBumpKeywords = map[string]bumpType{
+	"breaking": major,
+	"add":      minor,
+	"added":    minor,
+	"adds":     minor,
+	"feature":  minor,
+	"feat":     minor,
+}
+
+// inferBump guesses a bump type from the words of summary: the highest bump
+// among matching keywords, compared case-insensitively, or patch if none match.
+func inferBump(summary string, keywords map[string]bumpType) bumpType {
+	words := strings.FieldsFunc(strings.ToLower(summary), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	inferred := patch
+	for _, w := range words {
+		if b, ok := keywords[w]; ok && bumpPriority(b) > bumpPriority(inferred) {
+			inferred = b
+		}
+	}
+	return inferred
+}
+
+// defaultBumpLabels are the pull request labels add --from-labels maps to a
+// bump when the config does not define its own.
+var defaultBumpLabels = map[string]bumpType{
+	"semver:major":    major,
+	"semver:minor":    minor,
+	"semver:patch":    patch,
+	"semver:none":     none,
+	"breaking-change": major,
+}
+
+// readLabels reads the labels in a file exported by CI, one per line or
+// separated by commas. Blank entries are skipped.
+func readLabels(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	var labels []string
+	for _, field := range strings.FieldsFunc(string(data), func(r rune) bool { return r == '\n' || r == ',' }) {
+		if label := strings.TrimSpace(field); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// bumpFromLabels returns the highest bump that labels map to, compared
+// case-insensitively, and false when no label maps to a bump.
+func bumpFromLabels(labels []string, mapping map[string]bumpType) (bumpType, bool) {
+	var bump bumpType
+	found := false
+	for _, label := range labels {
+		b, ok := mapping[strings.ToLower(label)]
+		if ok && (!found || bumpPriority(b) > bumpPriority(bump)) {
+			bump, found = b, true
+		}
 	}
+	return bump, found
 }
 
 func bumpPriority(b bumpType) int {
@@ -1,15 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestParse(t *testing.T) {
 	content := "---\nmy-repo: minor\n---\n\nAdded something cool"
 
-	cs, err := parseChangeset(content, "test.md")
+	cs, err := parseChangeset(content, "test.md", parseOptions{})
 	if err != nil {
 		t.Fatalf("parseChangeset failed: %v", err)
 	}
@@ -33,10 +36,12 @@ func TestParseAllBumpTypes(t *testing.T) {
 		{"---\nrepo: patch\n---\n\nfix", patch},
 		{"---\nrepo: minor\n---\n\nfeat", minor},
 		{"---\nrepo: major\n---\n\nbreaking", major},
+		{"---\nrepo: none\n---\n\ndocs", none},
+		{"---\napi: none\nweb: patch\n---\n\nfix", patch},
 	}
 
 	for _, tt := range tests {
-		cs, err := parseChangeset(tt.input, "test.md")
+		cs, err := parseChangeset(tt.input, "test.md", parseOptions{})
 		if err != nil {
 			t.Fatalf("parseChangeset failed for %s: %v", tt.expected, err)
 		}
@@ -46,27 +51,254 @@ func TestParseAllBumpTypes(t *testing.T) {
 	}
 }
 
+func TestParseQuotedBump(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bumpType
+	}{
+		{"---\nrepo: \"minor\"\n---\n\nfeat", minor},
+		{"---\nrepo: 'major'\n---\n\nbreaking", major},
+		{"---\nrepo:  \"patch\" \n---\n\nfix", patch},
+		{"---\nrepo: patch\n---\n\nfix", patch},
+	}
+
+	for _, tt := range tests {
+		cs, err := parseChangeset(tt.input, "test.md", parseOptions{})
+		if err != nil {
+			t.Fatalf("parseChangeset failed for %q: %v", tt.input, err)
+		}
+		if cs.bump != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, cs.bump)
+		}
+	}
+}
+
+func TestParseMismatchedQuotes(t *testing.T) {
+	for _, input := range []string{"---\nrepo: \"minor'\n---\n\nx", "---\nrepo: \"minor\n---\n\nx"} {
+		if _, err := parseChangeset(input, "test.md", parseOptions{}); err == nil {
+			t.Errorf("expected error for %q, got nil", input)
+		}
+	}
+}
+
 func TestParseMissingFrontmatter(t *testing.T) {
-	_, err := parseChangeset("no frontmatter here", "test.md")
+	_, err := parseChangeset("no frontmatter here", "test.md", parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for missing frontmatter, got nil")
 	}
 }
 
 func TestParseMissingClosingDelimiter(t *testing.T) {
-	_, err := parseChangeset("---\nrepo: patch\nno closing", "test.md")
+	_, err := parseChangeset("---\nrepo: patch\nno closing", "test.md", parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for missing closing delimiter, got nil")
 	}
 }
 
 func TestParseInvalidBumpType(t *testing.T) {
-	_, err := parseChangeset("---\nrepo: invalid\n---\n\nmessage", "test.md")
+	_, err := parseChangeset("---\nrepo: invalid\n---\n\nmessage", "test.md", parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for invalid bump type, got nil")
 	}
 }
 
+func TestParseMultiplePackages(t *testing.T) {
+	content := "---\napi: patch\nweb: minor\n---\n\nShared change"
+
+	cs, err := parseChangeset(content, "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+
+	if cs.repoName != "api" {
+		t.Errorf("expected first package api as repo name, got %s", cs.repoName)
+	}
+	if cs.bump != minor {
+		t.Errorf("expected highest bump minor, got %s", cs.bump)
+	}
+	if b, ok := cs.bumpFor("api"); !ok || b != patch {
+		t.Errorf("expected api patch, got %s (found=%v)", b, ok)
+	}
+	if _, ok := cs.bumpFor("cli"); ok {
+		t.Error("expected cli not to be referenced")
+	}
+	if got := cs.content(); got != content+"\n" {
+		t.Errorf("content did not round-trip:\n%s", got)
+	}
+}
+
+func TestParseAuthor(t *testing.T) {
+	content := "---\napi: minor\nauthor: \"@alice\"\n---\n\nPaired on this"
+
+	cs, err := parseChangeset(content, "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if cs.author != "@alice" {
+		t.Errorf("expected author @alice, got %q", cs.author)
+	}
+	if len(cs.releases) != 1 {
+		t.Errorf("author should not be treated as a package, got %v", cs.releases)
+	}
+	if got := cs.content(); got != "---\napi: minor\nauthor: @alice\n---\n\nPaired on this\n" {
+		t.Errorf("unexpected content:\n%s", got)
+	}
+
+	if _, err := parseChangeset("---\nauthor: @alice\n---\n\nx", "test.md", parseOptions{}); err == nil {
+		t.Error("expected error for frontmatter with only an author")
+	}
+}
+
+func TestParseDetails(t *testing.T) {
+	content := "---\napi: minor\n---\n\n+++\nMigration notes\n---\nMore\n+++\n\nRenamed a flag"
+
+	cs, err := parseChangeset(content, "test.md", parseOptions{detailsDelimiter: "+++"})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if cs.details != "Migration notes\n---\nMore" {
+		t.Errorf("unexpected details %q", cs.details)
+	}
+	if cs.summary != "Renamed a flag" {
+		t.Errorf("unexpected summary %q", cs.summary)
+	}
+	if got := cs.content(); got != content+"\n" {
+		t.Errorf("content did not round-trip:\n%s", got)
+	}
+
+	cs, err = parseChangeset(content, "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if cs.details != "" || !strings.HasPrefix(cs.summary, "+++") {
+		t.Errorf("details should be ignored without a delimiter, got details %q summary %q", cs.details, cs.summary)
+	}
+
+	if _, err := parseChangeset("---\napi: minor\n---\n\n+++\nnever closed", "test.md", parseOptions{detailsDelimiter: "+++"}); err == nil {
+		t.Error("expected error for unclosed details block")
+	}
+	if _, err := parseChangeset("---\napi: minor\n---\n\n+++\nonly details\n+++", "test.md", parseOptions{detailsDelimiter: "+++"}); err == nil {
+		t.Error("expected error for details without a summary")
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	existing := []*changeset{
+		{slug: "a", releases: []release{{name: "api", bump: patch}}, summary: "Fix"},
+		{slug: "b", releases: []release{{name: "api", bump: minor}}, summary: "Feature"},
+	}
+
+	dup := findDuplicate(existing, &changeset{releases: []release{{name: "api", bump: minor}}, summary: "Feature"})
+	if dup == nil || dup.slug != "b" {
+		t.Errorf("expected duplicate b, got %v", dup)
+	}
+	if dup := findDuplicate(existing, &changeset{releases: []release{{name: "api", bump: major}}, summary: "Feature"}); dup != nil {
+		t.Errorf("expected no duplicate for a different bump, got %s", dup.slug)
+	}
+}
+
+func TestParseCollapse(t *testing.T) {
+	content := "---\napi: patch\ncollapse: Dependency updates\n---\n\nBump x/net to v0.30.0"
+
+	cs, err := parseChangeset(content, "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if cs.collapse != "Dependency updates" {
+		t.Errorf("expected collapse label, got %q", cs.collapse)
+	}
+	if len(cs.releases) != 1 {
+		t.Errorf("collapse should not be treated as a package, got %v", cs.releases)
+	}
+	if got := cs.content(); got != content+"\n" {
+		t.Errorf("content did not round-trip:\n%s", got)
+	}
+}
+
+func TestInferBump(t *testing.T) {
+	tests := []struct {
+		summary  string
+		expected bumpType
+	}{
+		{"Fixed a crash on empty input", patch},
+		{"Added JSON output", minor},
+		{"feat: support templates", minor},
+		{"BREAKING: removed the legacy flag, added a new one", major},
+		{"Fixed address parsing", patch},
+	}
+
+	for _, tt := range tests {
+		if got := inferBump(tt.summary, defaultBumpKeywords); got != tt.expected {
+			t.Errorf("inferBump(%q) = %s, expected %s", tt.summary, got, tt.expected)
+		}
+	}
+}
+
+func TestBumpFromLabels(t *testing.T) {
+	tests := []struct {
+		labels []string
+		want   bumpType
+		found  bool
+	}{
+		{[]string{"semver:patch"}, patch, true},
+		{[]string{"bug", "SemVer:Minor"}, minor, true},
+		{[]string{"semver:minor", "breaking-change"}, major, true},
+		{[]string{"semver:none"}, none, true},
+		{[]string{"semver:none", "semver:patch"}, patch, true},
+		{[]string{"bug", "documentation"}, "", false},
+		{nil, "", false},
+	}
+
+	for _, tt := range tests {
+		got, found := bumpFromLabels(tt.labels, defaultBumpLabels)
+		if got != tt.want || found != tt.found {
+			t.Errorf("bumpFromLabels(%q) = %q, %v; expected %q, %v", tt.labels, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestReadLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.txt")
+	os.WriteFile(path, []byte("bug, semver:minor\n\n  documentation  \n"), 0644)
+
+	labels, err := readLabels(path)
+	if err != nil {
+		t.Fatalf("readLabels failed: %v", err)
+	}
+	if fmt.Sprintf("%q", labels) != `["bug" "semver:minor" "documentation"]` {
+		t.Errorf("unexpected labels %q", labels)
+	}
+}
+
+func TestParseEmptyFrontmatter(t *testing.T) {
+	if _, err := parseChangeset("---\n\n---\n\nmessage", "test.md", parseOptions{}); err == nil {
+		t.Fatal("expected error for frontmatter without packages, got nil")
+	}
+}
+
+func TestParseLenientBump(t *testing.T) {
+	var warnings bytes.Buffer
+	opts := parseOptions{lenientBump: true, warnings: &warnings}
+
+	cs, err := parseChangeset("---\napi: feature\nweb: minor\n---\n\nImported", "imported.md", opts)
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if b, _ := cs.bumpFor("api"); b != patch {
+		t.Errorf("expected unknown bump to map to patch, got %s", b)
+	}
+	if cs.bump != minor {
+		t.Errorf("expected known bumps to be kept, got %s", cs.bump)
+	}
+	if got := warnings.String(); got != "warning: imported.md: unknown bump type \"feature\" for api, treating as patch\n" {
+		t.Errorf("unexpected warning %q", got)
+	}
+
+	if _, err := parseChangeset("---\napi: feature\n---\n\nImported", "imported.md", parseOptions{}); err == nil {
+		t.Error("expected strict mode to reject unknown bump types")
+	}
+}
+
 func TestFormat(t *testing.T) {
 	result := changesetContent("my-repo", minor, "Added feature")
 	expected := "---\nmy-repo: minor\n---\n\nAdded feature\n"
@@ -76,6 +308,104 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestParseWithoutBlankLine(t *testing.T) {
+	tests := []struct {
+		content string
+		summary string
+	}{
+		{"---\nmy-repo: patch\n---\nFixed bug", "Fixed bug"},
+		{"---\nmy-repo: patch\n---\nFixed bug\n\nMore detail", "Fixed bug\n\nMore detail"},
+		{"---\nmy-repo: patch\n---\n\n\nFixed bug\n\n", "Fixed bug"},
+	}
+
+	for _, tt := range tests {
+		cs, err := parseChangeset(tt.content, "test.md", parseOptions{})
+		if err != nil {
+			t.Fatalf("parseChangeset(%q) failed: %v", tt.content, err)
+		}
+		if cs.summary != tt.summary {
+			t.Errorf("parseChangeset(%q): summary = %q, expected %q", tt.content, cs.summary, tt.summary)
+		}
+	}
+}
+
+func TestContentRoundTrip(t *testing.T) {
+	// Both forms serialize to the canonical one, which parses back unchanged.
+	canonical := "---\nmy-repo: patch\nauthor: @me\n---\n\nFixed bug\n"
+	for _, content := range []string{
+		"---\nmy-repo: patch\nauthor: @me\n---\nFixed bug",
+		"---\nmy-repo: patch\nauthor: @me\n---\n\nFixed bug\n",
+		canonical,
+	} {
+		cs, err := parseChangeset(content, "test.md", parseOptions{})
+		if err != nil {
+			t.Fatalf("parseChangeset(%q) failed: %v", content, err)
+		}
+		if got := cs.content(); got != canonical {
+			t.Errorf("content() of %q = %q, expected %q", content, got, canonical)
+		}
+
+		again, err := parseChangeset(cs.content(), "test.md", parseOptions{})
+		if err != nil {
+			t.Fatalf("reparsing %q failed: %v", cs.content(), err)
+		}
+		if again.content() != canonical {
+			t.Errorf("round trip of %q is not stable: %q", content, again.content())
+		}
+	}
+}
+
+func TestParsePreservesIndentation(t *testing.T) {
+	body := "    go install example.com/tool@latest\n\n```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```"
+	content := "---\nmy-repo: minor\n---\n\n" + body + "\n\n"
+
+	cs, err := parseChangeset(content, "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if cs.summary != body {
+		t.Errorf("summary = %q, expected %q", cs.summary, body)
+	}
+
+	again, err := parseChangeset(cs.content(), "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("reparsing %q failed: %v", cs.content(), err)
+	}
+	if again.summary != body {
+		t.Errorf("round trip changed the summary to %q", again.summary)
+	}
+}
+
+func TestSummaryEntries(t *testing.T) {
+	tests := []struct {
+		summary string
+		want    []string
+	}{
+		{"Fixed a crash", []string{"Fixed a crash"}},
+		{"Reworked parsing\n\n- faster\n- stricter", []string{"Reworked parsing\n\n- faster\n- stricter"}},
+		{"- Added --json\n- Fixed the exit code", []string{"Added --json", "Fixed the exit code"}},
+		{"- Added --json\n\n- Fixed the exit code\n  when nothing is pending", []string{"Added --json", "Fixed the exit code\nwhen nothing is pending"}},
+		{"- Added output formats\n  - json\n  - yaml", []string{"Added output formats\n- json\n- yaml"}},
+	}
+
+	for _, tt := range tests {
+		got := summaryEntries(tt.summary)
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+			t.Errorf("summaryEntries(%q) = %q, expected %q", tt.summary, got, tt.want)
+		}
+	}
+}
+
+func TestParseBulletSummary(t *testing.T) {
+	cs, err := parseChangeset("---\nmy-repo: minor\n---\n\n- Added --json\n- Fixed the exit code\n", "test.md", parseOptions{})
+	if err != nil {
+		t.Fatalf("parseChangeset failed: %v", err)
+	}
+	if got := summaryEntries(cs.summary); len(got) != 2 || got[0] != "Added --json" || got[1] != "Fixed the exit code" {
+		t.Errorf("expected two entries from the parsed bullets, got %q", got)
+	}
+}
+
 func TestListChangesets(t *testing.T) {
 	dir := t.TempDir()
 
@@ -94,7 +424,7 @@ func TestListChangesets(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	changes, err := listChangesets(dir)
+	changes, err := listChangesets(dir, parseOptions{})
 	if err != nil {
 		t.Fatalf("listChangesets failed: %v", err)
 	}
@@ -107,7 +437,7 @@ func TestListChangesets(t *testing.T) {
 func TestParseWithHorizontalRuleInBody(t *testing.T) {
 	content := "---\nmy-repo: minor\n---\n\nSome summary\n\n---\n\nMore details after a horizontal rule"
 
-	cs, err := parseChangeset(content, "test.md")
+	cs, err := parseChangeset(content, "test.md", parseOptions{})
 	if err != nil {
 		t.Fatalf("parseChangeset failed: %v", err)
 	}
@@ -144,6 +474,8 @@ func TestHighestBump(t *testing.T) {
 		{[]bumpType{patch, minor, major}, major},
 		{[]bumpType{minor, patch}, minor},
 		{[]bumpType{major, patch}, major},
+		{[]bumpType{none}, none},
+		{[]bumpType{none, patch}, patch},
 	}
 
 	for _, tt := range tests {
@@ -167,7 +499,7 @@ func TestParseFileSuccess(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cs, err := parseFile(path)
+	cs, err := parseFile(path, parseOptions{})
 	if err != nil {
 		t.Fatalf("parseFile failed: %v", err)
 	}
@@ -181,24 +513,57 @@ func TestParseFileSuccess(t *testing.T) {
 	if cs.summary != "Fixed a bug" {
 		t.Errorf("expected summary 'Fixed a bug', got %q", cs.summary)
 	}
+	if cs.slug != "test" {
+		t.Errorf("expected slug test, got %q", cs.slug)
+	}
+}
+
+func TestParseFileMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.md")
+	content := "---\nrepo: patch\n---\n\n" + strings.Repeat("x", 100)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parseFile(path, parseOptions{maxSize: 50}); err == nil {
+		t.Fatal("expected error for changeset larger than maxSize")
+	}
+
+	cs, err := parseFile(path, parseOptions{maxSize: int64(len(content))})
+	if err != nil {
+		t.Fatalf("parseFile failed at exactly maxSize: %v", err)
+	}
+	if len(cs.summary) != 100 {
+		t.Errorf("expected full summary, got %d bytes", len(cs.summary))
+	}
+}
+
+func TestListChangesetsMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "small.md"), []byte("---\nrepo: patch\n---\n\nFix"), 0644)
+	os.WriteFile(filepath.Join(dir, "huge.md"), []byte("---\nrepo: patch\n---\n\n"+strings.Repeat("x", 1024)), 0644)
+
+	if _, err := listChangesets(dir, parseOptions{maxSize: 512}); err == nil {
+		t.Fatal("expected error when a changeset exceeds maxSize")
+	}
 }
 
 func TestParseFileNotFound(t *testing.T) {
-	_, err := parseFile("/nonexistent/changeset.md")
+	_, err := parseFile("/nonexistent/changeset.md", parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for missing file, got nil")
 	}
 }
 
 func TestParseInvalidFrontmatterFormat(t *testing.T) {
-	_, err := parseChangeset("---\nnocolonhere\n---\n\nmessage", "test.md")
+	_, err := parseChangeset("---\nnocolonhere\n---\n\nmessage", "test.md", parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for frontmatter without colon, got nil")
 	}
 }
 
 func TestListChangesetsInvalidDir(t *testing.T) {
-	_, err := listChangesets("/nonexistent/dir")
+	_, err := listChangesets("/nonexistent/dir", parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for nonexistent directory, got nil")
 	}
@@ -215,7 +580,7 @@ func TestListChangesetsWithSubdirectory(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	changes, err := listChangesets(dir)
+	changes, err := listChangesets(dir, parseOptions{})
 	if err != nil {
 		t.Fatalf("listChangesets failed: %v", err)
 	}
@@ -232,7 +597,7 @@ func TestListChangesetsParseError(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err := listChangesets(dir)
+	_, err := listChangesets(dir, parseOptions{})
 	if err == nil {
 		t.Fatal("expected error for invalid changeset file, got nil")
 	}
@@ -244,3 +609,53 @@ func TestBumpPriorityDefault(t *testing.T) {
 		t.Errorf("expected priority 0 for unknown bump type, got %d", result)
 	}
 }
+
+func TestValidate(t *testing.T) {
+	valid := &changeset{releases: []release{{name: "api", bump: patch}}, summary: "Fix"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid changeset, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cs   *changeset
+	}{
+		{"no packages", &changeset{summary: "Fix"}},
+		{"empty package name", &changeset{releases: []release{{name: "", bump: patch}}, summary: "Fix"}},
+		{"invalid bump", &changeset{releases: []release{{name: "api", bump: "huge"}}, summary: "Fix"}},
+		{"empty summary", &changeset{releases: []release{{name: "api", bump: patch}}, summary: "  \n"}},
+	}
+	for _, tt := range tests {
+		if err := tt.cs.Validate(); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+		}
+	}
+}
+
+func TestParseEmptySummary(t *testing.T) {
+	if _, err := parseChangeset("---\nrepo: patch\n---\n\n", "test.md", parseOptions{}); err == nil {
+		t.Fatal("expected error for empty summary, got nil")
+	}
+}
+
+func TestCollectChangesets(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "good.md"), []byte("---\nrepo: patch\n---\n\nFix"), 0644)
+	os.WriteFile(filepath.Join(dir, "bad.md"), []byte("no frontmatter"), 0644)
+	os.WriteFile(filepath.Join(dir, "worse.md"), []byte("---\nrepo: huge\n---\n\nx"), 0644)
+
+	changes, errs, err := collectChangesets(dir, parseOptions{})
+	if err != nil {
+		t.Fatalf("collectChangesets failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].slug != "good" {
+		t.Errorf("expected only good.md to parse, got %v", changes)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+
+	if _, _, err := collectChangesets(filepath.Join(dir, "missing"), parseOptions{}); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// stableChannel is the default release channel, released with plain versions
+// into CHANGELOG.md.
+const stableChannel = "stable"
+
+// channelNameRe matches channel names, which become a semver prerelease
+// identifier and part of a filename.
+var channelNameRe = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// validateChannel checks that name can be used as a release channel.
+func validateChannel(name string) error {
+	if !channelNameRe.MatchString(name) {
+		return fmt.Errorf("invalid channel %q, expected lowercase letters and digits starting with a letter", name)
+	}
+	return nil
+}
+
+// channelChangelogFile returns the changelog a channel is released into, e.g.
+// CHANGELOG.beta.md.
+func channelChangelogFile(channel string) string {
+	return strings.TrimSuffix(changelogFile, ".md") + "." + channel + ".md"
+}
+
+// nextChannelVersion computes the next version of a prerelease channel. The
+// line targets the stable version the pending changesets would produce: the
+// first release for a target is <target>-<channel>.1, and later ones advance
+// the counter (v1.3.0-beta.1 -> v1.3.0-beta.2). With allowPrereleaseBump, the
+// target of a prerelease stable version is computed as in calculateNextVersion,
+// as is a calendar version for date.
+func nextChannelVersion(cfg *config, channel string, changes []*changeset, allowPrereleaseBump bool, date time.Time) (string, error) {
+	inc, err := cfg.versionIncrementer(allowPrereleaseBump, date)
+	if err != nil {
+		return "", err
+	}
+	target, err := inc(cfg.Version, highestBump(changes))
+	if err != nil {
+		return "", err
+	}
+	tv, err := semver.NewVersion(strings.TrimPrefix(target, "v"))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to parse version %q: %w", ErrInvalidVersion, target, err)
+	}
+	base, err := tv.SetPrerelease("")
+	if err != nil {
+		return "", fmt.Errorf("failed to set prerelease of %q: %w", target, err)
+	}
+
+	pre := channel + ".1"
+	if current, ok := cfg.Channels[channel]; ok {
+		cv, err := semver.NewVersion(strings.TrimPrefix(current, "v"))
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to parse %s version %q: %w", ErrInvalidVersion, channel, current, err)
+		}
+		cb, _ := cv.SetPrerelease("")
+		if cb.Equal(&base) && strings.HasPrefix(cv.Prerelease(), channel+".") {
+			pre = incPrerelease(cv.Prerelease())
+		}
+	}
+
+	next, err := base.SetPrerelease(pre)
+	if err != nil {
+		return "", fmt.Errorf("failed to set prerelease of %q: %w", target, err)
+	}
+	return "v" + next.String(), nil
+}
+
+// releaseChannel releases the pending changesets on a prerelease channel. The
+// channel's version and changelog are updated, while the stable version and
+// the changesets are left alone so the stable release still includes them.
+func releaseChannel(p paths, scanner *bufio.Scanner, opts releaseOptions) error {
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	parseOpts := cfg.parseOptions()
+	parseOpts.lenientBump = opts.lenientBump
+	changes, err := listChangesets(p.changes, parseOpts)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		if opts.quiet {
+			return nil
+		}
+		return noChangesetsError(p.changes)
+	}
+	if highestBump(changes) == none {
+		return noVersionChange(opts)
+	}
+
+	date, _ := time.Parse(releaseDateLayout, opts.date) // validated by parseReleaseFlags
+	nextVerStr, err := nextChannelVersion(cfg, opts.channel, changes, opts.allowPrereleaseBump, date)
+	if err != nil {
+		return err
+	}
+
+	printDroppedSummaries(os.Stderr, changes, cfg.SummaryRender)
+
+	previous := cfg.Channels[opts.channel]
+	changelogOpts := cfg.changelogOptions()
+	changelogOpts.compareLinks = false
+	changelogOpts.previousVersion = previous
+	changelogOpts.channel = opts.channel
+	changelogOpts.date = date
+	if changelogOpts.template, err = loadChangelogTemplate(p.root, cfg, opts.templateFile); err != nil {
+		return err
+	}
+	changelogSection, err := buildChangelogSection(nextVerStr, changes, changelogOpts)
+	if err != nil {
+		return err
+	}
+	if err := checkGitTimeout(os.Stderr, cfg); err != nil {
+		return err
+	}
+
+	if opts.interactive {
+		ok, err := confirmRelease(scanner, nextVerStr, changelogSection)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	if opts.output != "" {
+		return renderReleaseNotes(releaseResult{Channel: opts.channel, Previous: previous, Version: nextVerStr}, changelogSection, opts)
+	}
+
+	resultPath := channelChangelogFile(opts.channel)
+	if err := prependChangelog(filepath.Join(p.root, resultPath), changelogSection, cfg.changelogHeading(), opts.replace, cfg.MaxChangelogSections); err != nil {
+		return err
+	}
+
+	if cfg.Channels == nil {
+		cfg.Channels = make(map[string]string)
+	}
+	cfg.Channels[opts.channel] = nextVerStr
+	if err := saveConfig(p.config, cfg); err != nil {
+		return err
+	}
+
+	if err := appendStepSummary(changelogSection); err != nil {
+		return err
+	}
+
+	var manifestPath string
+	if opts.manifest {
+		manifest := newReleaseManifest(nextVerStr, changes, changelogOpts)
+		if manifestPath, err = writeReleaseManifest(p, manifest); err != nil {
+			return err
+		}
+	}
+
+	return printReleaseResult(releaseResult{
+		Channel:       opts.channel,
+		Previous:      previous,
+		Version:       nextVerStr,
+		ChangelogPath: resultPath,
+		Consumed:      len(changes),
+		Manifest:      manifestPath,
+	}, opts.json, prettyJSON(opts.pretty))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextChannelVersion(t *testing.T) {
+	tests := []struct {
+		stable   string
+		current  string
+		bump     bumpType
+		expected string
+	}{
+		{"v1.2.0", "", minor, "v1.3.0-beta.1"},
+		{"v1.2.0", "v1.3.0-beta.1", minor, "v1.3.0-beta.2"},
+		{"v1.2.0", "v1.3.0-beta.2", patch, "v1.2.1-beta.1"},
+		{"v1.2.0", "v1.3.0-beta.4", major, "v2.0.0-beta.1"},
+		{"v1.2.0", "v1.3.0-rc.1", minor, "v1.3.0-beta.1"},
+	}
+	for _, tt := range tests {
+		cfg := &config{Version: tt.stable}
+		if tt.current != "" {
+			cfg.Channels = map[string]string{"beta": tt.current}
+		}
+		got, err := nextChannelVersion(cfg, "beta", []*changeset{{bump: tt.bump}}, false, time.Time{})
+		if err != nil {
+			t.Fatalf("nextChannelVersion(%s, %s, %s) failed: %v", tt.stable, tt.current, tt.bump, err)
+		}
+		if got != tt.expected {
+			t.Errorf("nextChannelVersion(%s, %s, %s) = %s, expected %s", tt.stable, tt.current, tt.bump, got, tt.expected)
+		}
+	}
+}
+
+func TestNextChannelVersionAllowPrereleaseBump(t *testing.T) {
+	cfg := &config{Version: "v2.0.0-rc.1"}
+	changes := []*changeset{{bump: major}}
+
+	if got, _ := nextChannelVersion(cfg, "beta", changes, false, time.Time{}); got != "v3.0.0-beta.1" {
+		t.Errorf("expected a major bump to target v3.0.0, got %s", got)
+	}
+	if got, _ := nextChannelVersion(cfg, "beta", changes, true, time.Time{}); got != "v2.0.0-beta.1" {
+		t.Errorf("expected --allow-prerelease-bump to target v2.0.0, got %s", got)
+	}
+}
+
+func TestCmdReleaseChannel(t *testing.T) {
+	p := setupProject(t, "v1.2.0", "---\ntest: minor\n---\n\nAdded feature")
+
+	for _, expected := range []string{"v1.3.0-beta.1", "v1.3.0-beta.2"} {
+		var err error
+		output := captureStdout(func() {
+			err = cmdRelease(p, newScanner(""), releaseOptions{channel: "beta", quiet: true})
+		})
+		if err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+		if strings.TrimSpace(output) != expected {
+			t.Errorf("expected %s, got %q", expected, strings.TrimSpace(output))
+		}
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.2.0" {
+		t.Errorf("expected stable version to stay v1.2.0, got %s", cfg.Version)
+	}
+	if cfg.Channels["beta"] != "v1.3.0-beta.2" {
+		t.Errorf("expected beta v1.3.0-beta.2, got %v", cfg.Channels)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.root, "CHANGELOG.beta.md"))
+	if err != nil {
+		t.Fatalf("CHANGELOG.beta.md not written: %v", err)
+	}
+	if !strings.Contains(string(data), "## v1.3.0-beta.2 - ") || !strings.Contains(string(data), " (beta)\n") {
+		t.Errorf("expected channel in header, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(p.root, "CHANGELOG.md")); !os.IsNotExist(err) {
+		t.Error("CHANGELOG.md should not be written by a channel release")
+	}
+	if _, err := os.Stat(filepath.Join(p.changes, "change-0.md")); err != nil {
+		t.Error("changesets should be kept for the stable release")
+	}
+}
+
+func TestParseReleaseFlagsChannel(t *testing.T) {
+	opts, err := parseReleaseFlags([]string{"--channel", "stable"})
+	if err != nil {
+		t.Fatalf("parseReleaseFlags failed: %v", err)
+	}
+	if opts.channel != "" {
+		t.Errorf("expected stable to select the default channel, got %q", opts.channel)
+	}
+
+	for _, args := range [][]string{
+		{"--channel", "Beta"},
+		{"--channel", "be ta"},
+		{"--channel", "beta", "--package", "api"},
+		{"--channel", "beta", "--all"},
+	} {
+		if _, err := parseReleaseFlags(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
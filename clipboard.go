@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// outputClipboard is the release --output value that copies the changelog
+// section to the system clipboard.
+const outputClipboard = "clipboard"
+
+// clipboardCommands are the clipboard tools tried in order; the first one
+// found on PATH receives the text on stdin.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip"},
+}
+
+// errNoClipboard is returned when none of clipboardCommands is installed.
+var errNoClipboard = errors.New("no clipboard tool found (tried pbcopy, wl-copy, xclip, xsel, clip)")
+
+// copyToClipboard copies text to the system clipboard.
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return errNoClipboard
+}
+
+// writeClipboardNotes copies section to the clipboard, printing it to w with
+// a warning when that is not possible. w is stderr for release, so stdout
+// keeps only the version or JSON result.
+func writeClipboardNotes(w io.Writer, section string) {
+	if err := copyToClipboard(section); err != nil {
+		fmt.Fprintf(w, "warning: could not copy release notes to the clipboard: %v; printing them instead\n", err)
+		fmt.Fprint(w, section)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "clipboard.txt")
+	orig := clipboardCommands
+	defer func() { clipboardCommands = orig }()
+	clipboardCommands = [][]string{{"changesets-missing-tool"}, {"sh", "-c", "cat > " + dest}}
+
+	if err := copyToClipboard("## v1.0.0\n"); err != nil {
+		t.Fatalf("copyToClipboard failed: %v", err)
+	}
+	data, _ := os.ReadFile(dest)
+	if string(data) != "## v1.0.0\n" {
+		t.Errorf("expected section in the clipboard, got %q", data)
+	}
+}
+
+func TestWriteReleaseNotesClipboardFallback(t *testing.T) {
+	orig := clipboardCommands
+	defer func() { clipboardCommands = orig }()
+	clipboardCommands = [][]string{{"changesets-missing-tool"}}
+
+	if err := copyToClipboard("x"); !errors.Is(err, errNoClipboard) {
+		t.Errorf("expected errNoClipboard, got %v", err)
+	}
+
+	var buf strings.Builder
+	writeClipboardNotes(&buf, "## v1.0.0\n")
+	if !strings.Contains(buf.String(), "warning: could not copy") || !strings.HasSuffix(buf.String(), "## v1.0.0\n") {
+		t.Errorf("expected the warning and the section, got %q", buf.String())
+	}
+
+	// The fallback stays off stdout, so --json output remains valid.
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{output: outputClipboard, json: true}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	var result releaseResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil || result.Version != "v1.0.1" {
+		t.Errorf("expected only the JSON result on stdout, got %q (%v)", output, err)
+	}
+}
@@ -4,9 +4,16 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
 )
 
 const (
@@ -15,11 +22,440 @@ const (
 	changesDir    = "changes"
 	readmeFile    = "README.md"
 	gitkeepFile   = ".gitkeep"
+	changelogFile = "CHANGELOG.md"
+	bumpFile      = ".bump" // optional per-branch default bump at the project root
+
+	defaultMaxChangesetSize = 1 << 20 // 1 MiB
+	defaultChangelogTitle   = "Changelog"
+	defaultGitTimeout       = "10s"
+
+	// changesetsDirEnv overrides the location of the .changesets directory.
+	// Relative values are resolved against the project root.
+	changesetsDirEnv = "CHANGESETS_DIR"
 )
 
 // config represents the .changesets/config.json file.
 type config struct {
-	Version string `json:"version"`
+	Version          string `json:"version"`
+	PreviousVersion  string `json:"previousVersion,omitempty"`
+	SlugSeparator    string `json:"slugSeparator,omitempty"`
+	MaxChangesetSize int64  `json:"maxChangesetSize,omitempty"`
+	RepoURL          string `json:"repoURL,omitempty"`
+	CompareLinks     bool   `json:"compareLinks,omitempty"`
+	StaleDays        int    `json:"staleDays,omitempty"`
+
+	// ChangelogTemplate is a text/template file, relative to the project root,
+	// used instead of the built-in changelog section format.
+	ChangelogTemplate string `json:"changelogTemplate,omitempty"`
+
+	// ShowAuthors credits each changeset's author in the changelog.
+	ShowAuthors bool `json:"showAuthors,omitempty"`
+
+	// WarnPatchOnly makes release warn when only patch changes are pending
+	// long after the last minor or major release.
+	WarnPatchOnly bool `json:"warnPatchOnly,omitempty"`
+
+	// MinGoVersion is the lowest go.mod "go" directive doctor accepts, e.g. "1.21".
+	MinGoVersion string `json:"minGoVersion,omitempty"`
+
+	// DetailsDelimiter enables a details block at the start of a changeset
+	// body, fenced by lines holding exactly this value (e.g. "+++").
+	DetailsDelimiter string `json:"detailsDelimiter,omitempty"`
+
+	// VersioningScheme selects how versions are incremented: "semver"
+	// (default) or "calver".
+	VersioningScheme string `json:"versioningScheme,omitempty"`
+
+	// ScopedSlugs prefixes changeset filenames created with add --package
+	// with the package name, e.g. api-brave-calm-fox.md.
+	ScopedSlugs bool `json:"scopedSlugs,omitempty"`
+
+	// BumpKeywords maps summary keywords to the bump add --bump-from-body
+	// infers from them, e.g. {"breaking": "major"}. Empty uses the built-in map.
+	BumpKeywords map[string]string `json:"bumpKeywords,omitempty"`
+
+	// SummaryMustMatch is a regular expression every changeset summary must
+	// match, checked by add and validate, e.g. `#\d+` to require an issue.
+	SummaryMustMatch string `json:"summaryMustMatch,omitempty"`
+
+	// UseFullModulePath makes add name the root module by its full module
+	// path, e.g. github.com/me/tools, instead of its last segment.
+	UseFullModulePath bool `json:"useFullModulePath,omitempty"`
+
+	// FlatLayout keeps changesets directly in .changesets instead of its
+	// changes subdirectory.
+	FlatLayout bool `json:"flatLayout,omitempty"`
+
+	// ChangelogTitle is the "# " heading written at the top of a new
+	// changelog. Existing changelogs keep their title.
+	ChangelogTitle string `json:"changelogTitle,omitempty"`
+
+	// MaxPending makes add warn once this many changesets are pending, as a
+	// nudge to release. Zero means no limit.
+	MaxPending int `json:"maxPending,omitempty"`
+
+	// ShowCommitSubject appends the subject of the commit that added each
+	// changeset to its changelog entry.
+	ShowCommitSubject bool `json:"showCommitSubject,omitempty"`
+
+	// GitTimeout bounds how long each git command may run, as a Go duration
+	// such as "30s". "0" disables the limit.
+	GitTimeout string `json:"gitTimeout,omitempty"`
+
+	// FailOnGitTimeout makes a release fail when git times out, instead of
+	// leaving out the commit information with a warning.
+	FailOnGitTimeout bool `json:"failOnGitTimeout,omitempty"`
+
+	// BumpLabels maps pull request labels to the bump add --from-labels
+	// uses, e.g. {"semver:minor": "minor"}. Empty uses the built-in map.
+	BumpLabels map[string]string `json:"bumpLabels,omitempty"`
+
+	// MaintainIndex makes release record every consumed changeset in
+	// .changesets/index.json, which find searches.
+	MaintainIndex bool `json:"maintainIndex,omitempty"`
+
+	// SummaryRender selects how multi-line summaries fit into one changelog
+	// entry: "first-line" (default), "collapse" or "indent".
+	SummaryRender string `json:"summaryRender,omitempty"`
+
+	// MaxChangelogSections is how many release sections CHANGELOG.md keeps;
+	// older ones move to CHANGELOG.archive.md. Zero keeps them all.
+	MaxChangelogSections int `json:"maxChangelogSections,omitempty"`
+
+	// Channels holds the released version of each prerelease channel, keyed
+	// by channel name, e.g. {"beta": "v1.3.0-beta.2"}.
+	Channels map[string]string `json:"channels,omitempty"`
+
+	// Packages holds the released version of each workspace package, keyed by
+	// package name. Single-module projects only use Version.
+	Packages map[string]string `json:"packages,omitempty"`
+}
+
+// configField describes a single config.json field for `changesets config schema`.
+type configField struct {
+	key         string            // JSON key in config.json
+	description string            // one-line description
+	value       func(*config) any // reads the field from a config
+}
+
+// configFields lists every config.json field in the order they are documented.
+var configFields = []configField{
+	{
+		key:         "version",
+		description: "Current released version of the project",
+		value:       func(c *config) any { return c.Version },
+	},
+	{
+		key:         "previousVersion",
+		description: "Version released before the current one, used for compare links",
+		value:       func(c *config) any { return c.PreviousVersion },
+	},
+	{
+		key:         "slugSeparator",
+		description: "Single character joining the words of generated changeset filenames",
+		value:       func(c *config) any { return c.SlugSeparator },
+	},
+	{
+		key:         "maxChangesetSize",
+		description: "Maximum size in bytes of a changeset file; larger files are rejected",
+		value:       func(c *config) any { return c.MaxChangesetSize },
+	},
+	{
+		key:         "repoURL",
+		description: "Repository URL used to build links in the changelog",
+		value:       func(c *config) any { return c.RepoURL },
+	},
+	{
+		key:         "compareLinks",
+		description: "Maintain Keep a Changelog style compare links (requires repoURL)",
+		value:       func(c *config) any { return c.CompareLinks },
+	},
+	{
+		key:         "staleDays",
+		description: "Warn about changesets older than this many days (0 disables)",
+		value:       func(c *config) any { return c.StaleDays },
+	},
+	{
+		key:         "changelogTemplate",
+		description: "Template file, relative to the project root, used to render changelog sections",
+		value:       func(c *config) any { return c.ChangelogTemplate },
+	},
+	{
+		key:         "showAuthors",
+		description: "Credit the author recorded in each changeset in the changelog",
+		value:       func(c *config) any { return c.ShowAuthors },
+	},
+	{
+		key:         "warnPatchOnly",
+		description: "Warn on patch-only releases long after the last minor or major release",
+		value:       func(c *config) any { return c.WarnPatchOnly },
+	},
+	{
+		key:         "minGoVersion",
+		description: "Lowest go directive in go.mod that doctor accepts (empty disables the check)",
+		value:       func(c *config) any { return c.MinGoVersion },
+	},
+	{
+		key:         "detailsDelimiter",
+		description: "Fence for an optional details block at the start of a changeset body (empty disables it)",
+		value:       func(c *config) any { return c.DetailsDelimiter },
+	},
+	{
+		key:         "versioningScheme",
+		description: "How versions are incremented: semver, or calver (vYYYY.M.N with a counter per month)",
+		value:       func(c *config) any { return c.VersioningScheme },
+	},
+	{
+		key:         "scopedSlugs",
+		description: "Prefix changeset filenames created with add --package with the package name",
+		value:       func(c *config) any { return c.ScopedSlugs },
+	},
+	{
+		key:         "bumpKeywords",
+		description: "Summary keywords mapped to the bump add --bump-from-body infers (empty uses built-in keywords)",
+		value:       func(c *config) any { return c.BumpKeywords },
+	},
+	{
+		key:         "summaryMustMatch",
+		description: "Regular expression every changeset summary must match in add and validate (empty disables the check)",
+		value:       func(c *config) any { return c.SummaryMustMatch },
+	},
+	{
+		key:         "useFullModulePath",
+		description: "Name the root module in new changesets by its full module path instead of the last segment",
+		value:       func(c *config) any { return c.UseFullModulePath },
+	},
+	{
+		key:         "flatLayout",
+		description: "Keep changesets directly in .changesets instead of .changesets/changes (set by init --flat)",
+		value:       func(c *config) any { return c.FlatLayout },
+	},
+	{
+		key:         "changelogTitle",
+		description: "Heading written at the top of a new changelog",
+		value:       func(c *config) any { return c.ChangelogTitle },
+	},
+	{
+		key:         "maxPending",
+		description: "Number of pending changesets at which add warns to release first; 0 for no limit",
+		value:       func(c *config) any { return c.MaxPending },
+	},
+	{
+		key:         "showCommitSubject",
+		description: "Append the subject of the commit that added each changeset to its changelog entry",
+		value:       func(c *config) any { return c.ShowCommitSubject },
+	},
+	{
+		key:         "gitTimeout",
+		description: "How long each git command may run, as a Go duration such as \"30s\"; \"0\" disables the limit",
+		value:       func(c *config) any { return c.GitTimeout },
+	},
+	{
+		key:         "failOnGitTimeout",
+		description: "Fail a release when git times out instead of omitting commit SHAs with a warning",
+		value:       func(c *config) any { return c.FailOnGitTimeout },
+	},
+	{
+		key:         "bumpLabels",
+		description: "Pull request labels mapped to the bump add --from-labels uses (empty uses built-in labels)",
+		value:       func(c *config) any { return c.BumpLabels },
+	},
+	{
+		key:         "maintainIndex",
+		description: "Record released changesets in .changesets/index.json so find can search them",
+		value:       func(c *config) any { return c.MaintainIndex },
+	},
+	{
+		key:         "summaryRender",
+		description: "How multi-line summaries are rendered in the changelog: first-line, collapse, or indent",
+		value:       func(c *config) any { return c.SummaryRender },
+	},
+	{
+		key:         "maxChangelogSections",
+		description: "Release sections kept in the changelog before older ones move to the archive file (0 keeps all)",
+		value:       func(c *config) any { return c.MaxChangelogSections },
+	},
+	{
+		key:         "channels",
+		description: "Released version of each prerelease channel, used by release --channel",
+		value:       func(c *config) any { return c.Channels },
+	},
+	{
+		key:         "packages",
+		description: "Released version of each workspace package, used by release --package",
+		value:       func(c *config) any { return c.Packages },
+	},
+}
+
+// withDefaults returns a copy of the config with unset fields filled in.
+func (c *config) withDefaults() *config {
+	out := *c
+	if out.Version == "" {
+		out.Version = "v0.0.0"
+	}
+	if out.SlugSeparator == "" {
+		out.SlugSeparator = defaultSlugSeparator
+	}
+	if out.MaxChangesetSize == 0 {
+		out.MaxChangesetSize = defaultMaxChangesetSize
+	}
+	if out.VersioningScheme == "" {
+		out.VersioningScheme = schemeSemver
+	}
+	if out.ChangelogTitle == "" {
+		out.ChangelogTitle = defaultChangelogTitle
+	}
+	if out.GitTimeout == "" {
+		out.GitTimeout = defaultGitTimeout
+	}
+	if out.SummaryRender == "" {
+		out.SummaryRender = summaryRenderFirstLine
+	}
+	if len(out.BumpKeywords) == 0 {
+		out.BumpKeywords = bumpNames(defaultBumpKeywords)
+	}
+	if len(out.BumpLabels) == 0 {
+		out.BumpLabels = bumpNames(defaultBumpLabels)
+	}
+	return &out
+}
+
+// bumpNames converts a map of bumps to the form config.json stores it in.
+func bumpNames(bumps map[string]bumpType) map[string]string {
+	names := make(map[string]string, len(bumps))
+	for key, b := range bumps {
+		names[key] = string(b)
+	}
+	return names
+}
+
+// changelogHeading returns the heading line for a new changelog, accepting the
+// title with or without its leading "#".
+func (c *config) changelogHeading() string {
+	title := strings.TrimSpace(strings.TrimLeft(c.withDefaults().ChangelogTitle, "#"))
+	return "# " + title
+}
+
+// bumpKeywords returns the keywords used to infer a bump from a summary,
+// lowercased, falling back to defaultBumpKeywords when none are configured.
+func (c *config) bumpKeywords() (map[string]bumpType, error) {
+	if len(c.BumpKeywords) == 0 {
+		return defaultBumpKeywords, nil
+	}
+
+	keywords := make(map[string]bumpType, len(c.BumpKeywords))
+	for word, value := range c.BumpKeywords {
+		b, err := parseBumpType(value)
+		if err != nil {
+			return nil, fmt.Errorf("bumpKeywords %q: %w", word, err)
+		}
+		keywords[strings.ToLower(word)] = b
+	}
+	return keywords, nil
+}
+
+// bumpLabels returns the labels add --from-labels maps to bumps, lowercased,
+// falling back to defaultBumpLabels when none are configured.
+func (c *config) bumpLabels() (map[string]bumpType, error) {
+	if len(c.BumpLabels) == 0 {
+		return defaultBumpLabels, nil
+	}
+
+	labels := make(map[string]bumpType, len(c.BumpLabels))
+	for label, value := range c.BumpLabels {
+		b, err := parseBumpType(value)
+		if err != nil {
+			return nil, fmt.Errorf("bumpLabels %q: %w", label, err)
+		}
+		labels[strings.ToLower(label)] = b
+	}
+	return labels, nil
+}
+
+// summaryPattern compiles summaryMustMatch, returning nil when it is unset.
+func (c *config) summaryPattern() (*regexp.Regexp, error) {
+	if c.SummaryMustMatch == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(c.SummaryMustMatch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid summaryMustMatch %q: %w", c.SummaryMustMatch, err)
+	}
+	return re, nil
+}
+
+// gitTimeout returns the parsed gitTimeout, defaulting to defaultGitTimeout.
+// Zero means git commands run without a limit.
+func (c *config) gitTimeout() (time.Duration, error) {
+	value := c.withDefaults().GitTimeout
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid gitTimeout %q, expected a duration such as \"30s\"", value)
+	}
+	return d, nil
+}
+
+// checkSummary reports an error when summary does not match re. A nil re
+// accepts every summary.
+func checkSummary(re *regexp.Regexp, summary string) error {
+	if re != nil && !re.MatchString(summary) {
+		return fmt.Errorf("summary does not match the required pattern %q", re.String())
+	}
+	return nil
+}
+
+// incrementVersion computes the version after current using the configured
+// versioning scheme.
+func (c *config) incrementVersion(current string, bump bumpType) (string, error) {
+	inc, err := versionIncrementerFor(c.VersioningScheme, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	return inc(current, bump)
+}
+
+// versionIncrementer returns the incrementer for the configured versioning
+// scheme for a release on date (zero for today), wrapped with prereleaseBump
+// when allowPrereleaseBump is set.
+func (c *config) versionIncrementer(allowPrereleaseBump bool, date time.Time) (versionIncrementer, error) {
+	inc, err := versionIncrementerFor(c.VersioningScheme, date)
+	if err != nil {
+		return nil, err
+	}
+	if allowPrereleaseBump {
+		inc = prereleaseBump(inc)
+	}
+	return inc, nil
+}
+
+// packageVersion returns the released version of a workspace package,
+// defaulting to v0.0.0 for packages that have never been released.
+func (c *config) packageVersion(name string) string {
+	if v := c.Packages[name]; v != "" {
+		return v
+	}
+	return "v0.0.0"
+}
+
+// changelogOptions returns the changelog rendering options derived from the config.
+func (c *config) changelogOptions() changelogOptions {
+	return changelogOptions{
+		compareLinks:    c.CompareLinks && c.RepoURL != "",
+		showAuthors:     c.ShowAuthors,
+		showSubjects:    c.ShowCommitSubject,
+		repoURL:         c.RepoURL,
+		previousVersion: c.PreviousVersion,
+		summaryRender:   c.SummaryRender,
+	}
+}
+
+// parseOptions returns the changeset parsing options derived from the config.
+func (c *config) parseOptions() parseOptions {
+	return parseOptions{
+		maxSize:          c.withDefaults().MaxChangesetSize,
+		warnings:         os.Stderr,
+		detailsDelimiter: c.DetailsDelimiter,
+	}
 }
 
 // paths holds resolved absolute paths for the changesets directory structure.
@@ -55,8 +491,15 @@ func findRoot() (string, error) {
 }
 
 // newPaths returns all changesets-related paths relative to the given root.
+// The .changesets directory can be relocated with the CHANGESETS_DIR env var.
 func newPaths(root string) paths {
 	cs := filepath.Join(root, changesetsDir)
+	if dir := os.Getenv(changesetsDirEnv); dir != "" {
+		cs = dir
+		if !filepath.IsAbs(cs) {
+			cs = filepath.Join(root, cs)
+		}
+	}
 	return paths{
 		root:       root,
 		changesets: cs,
@@ -67,6 +510,34 @@ func newPaths(root string) paths {
 	}
 }
 
+// flat returns the paths for the flat layout, where changesets live directly
+// in .changesets and there is no changes subdirectory or .gitkeep.
+func (p paths) flat() paths {
+	p.changes = p.changesets
+	p.gitkeep = ""
+	return p
+}
+
+// ensureWithinRoot returns an error unless dir resolves to a location inside root.
+// Symlinks are resolved first, so a link pointing outside the project is rejected.
+func ensureWithinRoot(root, dir string) error {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("resolve project root: %w", err)
+	}
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", dir, err)
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolvedDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("changes directory %s is outside the project root %s", resolvedDir, resolvedRoot)
+	}
+
+	return nil
+}
+
 // loadConfig reads and parses the config.json file.
 func loadConfig(configPath string) (*config, error) {
 	data, err := os.ReadFile(configPath)
@@ -82,25 +553,134 @@ func loadConfig(configPath string) (*config, error) {
 	return &cfg, nil
 }
 
+// loadValidConfig reads config.json like loadConfig and also checks it with
+// Validate, so a corrupt config is reported before anything is written.
+func loadValidConfig(configPath string) (*config, error) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", filepath.Base(configPath), err)
+	}
+	return cfg, nil
+}
+
+// Validate checks that the version is present and every version recorded in
+// the config parses as semver, and that the versioning scheme is known.
+func (c *config) Validate() error {
+	if c.Version == "" {
+		return fmt.Errorf("%w: version is missing", ErrInvalidVersion)
+	}
+	if err := checkConfigVersion("version", c.Version); err != nil {
+		return err
+	}
+	if c.PreviousVersion != "" {
+		if err := checkConfigVersion("previousVersion", c.PreviousVersion); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range []struct {
+		name     string
+		versions map[string]string
+	}{
+		{"packages", c.Packages},
+		{"channels", c.Channels},
+	} {
+		names := make([]string, 0, len(field.versions))
+		for name := range field.versions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := checkConfigVersion(field.name+"."+name, field.versions[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := c.gitTimeout(); err != nil {
+		return err
+	}
+	switch c.SummaryRender {
+	case "", summaryRenderFirstLine, summaryRenderCollapse, summaryRenderIndent:
+	default:
+		return fmt.Errorf("invalid summaryRender %q, expected %s, %s, or %s", c.SummaryRender, summaryRenderFirstLine, summaryRenderCollapse, summaryRenderIndent)
+	}
+	if c.MaxChangelogSections < 0 {
+		return fmt.Errorf("invalid maxChangelogSections %d, expected zero or a positive number", c.MaxChangelogSections)
+	}
+
+	_, err := versionIncrementerFor(c.VersioningScheme, time.Time{})
+	return err
+}
+
+// checkConfigVersion reports an ErrInvalidVersion error naming the config
+// field when version does not parse.
+func checkConfigVersion(field, version string) error {
+	if _, err := semver.NewVersion(strings.TrimPrefix(version, "v")); err != nil {
+		return fmt.Errorf("%w: %s %q is not a semantic version: %w", ErrInvalidVersion, field, version, err)
+	}
+	return nil
+}
+
 // saveConfig writes the config back to disk with indentation.
 func saveConfig(configPath string, cfg *config) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := marshalConfig(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 
-	data = append(data, '\n')
+	// Write to a temp file in the same directory and rename it into place, so
+	// an interrupted or concurrent write never leaves a truncated config.
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), ".config-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once the rename succeeds
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), configPath); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
 	return nil
 }
 
+// marshalConfig returns cfg as saveConfig writes it: indented JSON with a
+// trailing newline.
+func marshalConfig(cfg *config) ([]byte, error) {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
 // moduleName reads go.mod and extracts the last segment of the module path.
 // For example, "github.com/nesymno/changesets" returns "changesets".
 func moduleName(root string) (string, error) {
+	modulePath, err := readModulePath(root)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(modulePath, "/")
+	return parts[len(parts)-1], nil
+}
+
+// readModulePath reads go.mod and returns the full module path.
+func readModulePath(root string) (string, error) {
 	f, err := os.Open(filepath.Join(root, "go.mod"))
 	if err != nil {
 		return "", fmt.Errorf("open go.mod: %w", err)
@@ -112,9 +692,7 @@ func moduleName(root string) (string, error) {
 		line := strings.TrimSpace(scanner.Text())
 		if strings.HasPrefix(line, "module ") {
 			modulePath := strings.TrimPrefix(line, "module ")
-			modulePath = strings.TrimSpace(modulePath)
-			parts := strings.Split(modulePath, "/")
-			return parts[len(parts)-1], nil
+			return strings.TrimSpace(modulePath), nil
 		}
 	}
 
@@ -124,3 +702,82 @@ func moduleName(root string) (string, error) {
 
 	return "", fmt.Errorf("module directive not found in go.mod")
 }
+
+// goModDirectives holds the Go version requirements declared in go.mod.
+type goModDirectives struct {
+	goVersion string // version from the "go" directive, e.g. "1.21" or "1.21.0"; empty when absent
+	toolchain string // toolchain from the "toolchain" directive, e.g. "go1.22.1"; empty when absent
+}
+
+// goDirective reads go.mod in root and returns its "go" and "toolchain"
+// directives. Missing directives are left empty rather than reported as errors.
+func goDirective(root string) (goModDirectives, error) {
+	var d goModDirectives
+
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return d, fmt.Errorf("open go.mod: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			d.goVersion = fields[1]
+		case "toolchain":
+			d.toolchain = fields[1]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return d, fmt.Errorf("read go.mod: %w", err)
+	}
+
+	return d, nil
+}
+
+// printConfigSchema writes every config field with its key, default value, and description.
+func printConfigSchema(w io.Writer) error {
+	defaults := (&config{}).withDefaults()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tDEFAULT\tDESCRIPTION")
+	for _, f := range configFields {
+		def, err := json.Marshal(f.value(defaults))
+		if err != nil {
+			return fmt.Errorf("failed to marshal default for %s: %w", f.key, err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.key, def, f.description)
+	}
+
+	return tw.Flush()
+}
+
+// printEffectiveConfig writes cfg with defaults applied as a JSON object
+// holding every field, in the order of configFields.
+func printEffectiveConfig(w io.Writer, cfg *config) error {
+	effective := cfg.withDefaults()
+
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for i, f := range configFields {
+		value, err := json.MarshalIndent(f.value(effective), "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", f.key, err)
+		}
+		fmt.Fprintf(&sb, "  %q: %s", f.key, value)
+		if i < len(configFields)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
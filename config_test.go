@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -46,6 +49,75 @@ func TestLoadMissing(t *testing.T) {
 	}
 }
 
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     config
+		wantErr string
+	}{
+		{config{Version: "v1.2.0"}, ""},
+		{config{Version: "1.2.0-rc.1", PreviousVersion: "v1.1.0"}, ""},
+		{config{Version: "v2024.3.0", VersioningScheme: schemeCalver}, ""},
+		{config{}, "version is missing"},
+		{config{Version: "garbage"}, `version "garbage" is not a semantic version`},
+		{config{Version: "v1.0.0", PreviousVersion: "v1"}, ""},
+		{config{Version: "v1.0.0", PreviousVersion: "next"}, `previousVersion "next"`},
+		{config{Version: "v1.0.0", Packages: map[string]string{"api": "v0.1.0", "web": "latest"}}, `packages.web "latest"`},
+		{config{Version: "v1.0.0", Channels: map[string]string{"beta": "beta.1"}}, `channels.beta "beta.1"`},
+		{config{Version: "v1.0.0", VersioningScheme: "romver"}, "unknown versioning scheme"},
+		{config{Version: "v1.0.0", GitTimeout: "0"}, ""},
+		{config{Version: "v1.0.0", GitTimeout: "2m"}, ""},
+		{config{Version: "v1.0.0", GitTimeout: "10"}, `invalid gitTimeout "10"`},
+		{config{Version: "v1.0.0", GitTimeout: "-5s"}, `invalid gitTimeout "-5s"`},
+		{config{Version: "v1.0.0", SummaryRender: summaryRenderIndent}, ""},
+		{config{Version: "v1.0.0", SummaryRender: "wrap"}, `invalid summaryRender "wrap"`},
+		{config{Version: "v1.0.0", MaxChangelogSections: 10}, ""},
+		{config{Version: "v1.0.0", MaxChangelogSections: -1}, "invalid maxChangelogSections -1"},
+	}
+
+	for _, tt := range tests {
+		err := tt.cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%+v) failed: %v", tt.cfg, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate(%+v) = %v, expected error containing %q", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadValidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"version": "v1.x"}`), 0644)
+
+	if _, err := loadConfig(path); err != nil {
+		t.Fatalf("loadConfig should not validate, got %v", err)
+	}
+	_, err := loadValidConfig(path)
+	if !errors.Is(err, ErrInvalidVersion) || !strings.Contains(err.Error(), "invalid config config.json") {
+		t.Errorf("expected ErrInvalidVersion naming the file, got %v", err)
+	}
+}
+
+func TestChangelogHeading(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"", "# Changelog"},
+		{"Release Notes", "# Release Notes"},
+		{"# Release Notes", "# Release Notes"},
+	}
+	for _, tt := range tests {
+		cfg := &config{ChangelogTitle: tt.title}
+		if got := cfg.changelogHeading(); got != tt.expected {
+			t.Errorf("changelogHeading() with title %q = %q, expected %q", tt.title, got, tt.expected)
+		}
+	}
+}
+
 func TestModuleName(t *testing.T) {
 	dir := t.TempDir()
 	gomod := filepath.Join(dir, "go.mod")
@@ -88,6 +160,38 @@ func TestModuleNameNoDirective(t *testing.T) {
 	}
 }
 
+func TestGoDirective(t *testing.T) {
+	for _, directive := range []string{"1.21", "1.21.0"} {
+		dir := t.TempDir()
+		content := "module example.com/x\n\ngo " + directive + "\n\ntoolchain go1.22.1\n"
+		os.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0644)
+
+		got, err := goDirective(dir)
+		if err != nil {
+			t.Fatalf("goDirective failed: %v", err)
+		}
+		if got.goVersion != directive || got.toolchain != "go1.22.1" {
+			t.Errorf("expected go %s with toolchain go1.22.1, got %+v", directive, got)
+		}
+	}
+}
+
+func TestGoDirectiveMissing(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := goDirective(dir); err == nil {
+		t.Error("expected error for missing go.mod")
+	}
+
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/x\n"), 0644)
+	got, err := goDirective(dir)
+	if err != nil {
+		t.Fatalf("expected no error for go.mod without a go directive, got %v", err)
+	}
+	if got != (goModDirectives{}) {
+		t.Errorf("expected empty directives, got %+v", got)
+	}
+}
+
 func TestNewPaths(t *testing.T) {
 	p := newPaths("/project")
 
@@ -105,6 +209,27 @@ func TestNewPaths(t *testing.T) {
 	}
 }
 
+func TestNewPathsEnvOverride(t *testing.T) {
+	t.Setenv(changesetsDirEnv, "build/changesets")
+	p := newPaths("/project")
+
+	if p.changesets != "/project/build/changesets" {
+		t.Errorf("expected relative override under root, got %s", p.changesets)
+	}
+	if p.config != "/project/build/changesets/config.json" {
+		t.Errorf("expected config inside override, got %s", p.config)
+	}
+
+	t.Setenv(changesetsDirEnv, "/tmp/changesets")
+	p = newPaths("/project")
+	if p.changes != "/tmp/changesets/changes" {
+		t.Errorf("expected absolute override to be used as-is, got %s", p.changes)
+	}
+	if p.root != "/project" {
+		t.Errorf("expected root to be unaffected, got %s", p.root)
+	}
+}
+
 func TestFindRoot(t *testing.T) {
 	root, err := findRoot()
 	if err != nil {
@@ -139,3 +264,191 @@ func TestSaveConfigWriteError(t *testing.T) {
 		t.Fatal("expected error writing to nonexistent path, got nil")
 	}
 }
+
+func TestSaveConfigRenameErrorCleansUp(t *testing.T) {
+	dir := t.TempDir()
+	// A non-empty directory at the config path makes the final rename fail.
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.MkdirAll(filepath.Join(configPath, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveConfig(configPath, &config{Version: "v1.0.0"}); err == nil {
+		t.Fatal("expected rename error, got nil")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected temp file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestSaveConfigPermissions(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := saveConfig(configPath, &config{Version: "v1.0.0"}); err != nil {
+		t.Fatalf("saveConfig failed: %v", err)
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("expected mode 0644, got %v", info.Mode().Perm())
+	}
+}
+
+func TestBumpKeywords(t *testing.T) {
+	keywords, err := (&config{}).bumpKeywords()
+	if err != nil || keywords["breaking"] != major {
+		t.Errorf("expected the default keywords, got %v (%v)", keywords, err)
+	}
+
+	keywords, err = (&config{BumpKeywords: map[string]string{"Deps": "patch", "API": "major"}}).bumpKeywords()
+	if err != nil {
+		t.Fatalf("bumpKeywords failed: %v", err)
+	}
+	if len(keywords) != 2 || keywords["api"] != major || keywords["deps"] != patch {
+		t.Errorf("expected lowercased configured keywords, got %v", keywords)
+	}
+
+	if _, err := (&config{BumpKeywords: map[string]string{"api": "huge"}}).bumpKeywords(); err == nil {
+		t.Error("expected error for an invalid bump type")
+	}
+}
+
+func TestCheckSummary(t *testing.T) {
+	re, err := (&config{SummaryMustMatch: `#\d+`}).summaryPattern()
+	if err != nil {
+		t.Fatalf("summaryPattern failed: %v", err)
+	}
+	if err := checkSummary(re, "Fixed a crash (#42)"); err != nil {
+		t.Errorf("expected matching summary to pass, got %v", err)
+	}
+	if err := checkSummary(re, "Fixed a crash"); err == nil || !strings.Contains(err.Error(), `"#\\d+"`) {
+		t.Errorf("expected error naming the pattern, got %v", err)
+	}
+
+	re, err = (&config{}).summaryPattern()
+	if err != nil || re != nil {
+		t.Errorf("expected no pattern when unset, got %v (%v)", re, err)
+	}
+	if err := checkSummary(nil, "anything"); err != nil {
+		t.Errorf("expected no check without a pattern, got %v", err)
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	cfg := (&config{}).withDefaults()
+	if cfg.Version != "v0.0.0" {
+		t.Errorf("expected default version v0.0.0, got %s", cfg.Version)
+	}
+
+	cfg = (&config{Version: "v1.2.3"}).withDefaults()
+	if cfg.Version != "v1.2.3" {
+		t.Errorf("expected explicit version to be kept, got %s", cfg.Version)
+	}
+}
+
+func TestParseOptionsFromConfig(t *testing.T) {
+	if got := (&config{}).parseOptions().maxSize; got != defaultMaxChangesetSize {
+		t.Errorf("expected default max size %d, got %d", defaultMaxChangesetSize, got)
+	}
+	if got := (&config{MaxChangesetSize: 42}).parseOptions().maxSize; got != 42 {
+		t.Errorf("expected max size 42, got %d", got)
+	}
+}
+
+func TestWithDefaultsDoesNotModifyOriginal(t *testing.T) {
+	orig := &config{}
+	orig.withDefaults()
+	if orig.Version != "" {
+		t.Errorf("expected original config to be untouched, got version %q", orig.Version)
+	}
+}
+
+func TestPrintConfigSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printConfigSchema(&buf); err != nil {
+		t.Fatalf("printConfigSchema failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "KEY") {
+		t.Errorf("expected header line, got %q", output)
+	}
+	for _, f := range configFields {
+		if !strings.Contains(output, f.key) {
+			t.Errorf("schema missing field %s", f.key)
+		}
+		if !strings.Contains(output, f.description) {
+			t.Errorf("schema missing description for %s", f.key)
+		}
+	}
+	if !strings.Contains(output, `"v0.0.0"`) {
+		t.Error("schema missing default version")
+	}
+	if !strings.Contains(output, `"breaking-change":"major"`) {
+		t.Error("schema missing the built-in bump labels")
+	}
+}
+
+func TestEnsureWithinRoot(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".changesets", "changes")
+	os.MkdirAll(dir, 0755)
+
+	if err := ensureWithinRoot(root, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ensureWithinRoot(root, root); err != nil {
+		t.Fatalf("root itself should be allowed: %v", err)
+	}
+}
+
+func TestEnsureWithinRootOutside(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	if err := ensureWithinRoot(root, outside); err == nil {
+		t.Fatal("expected error for directory outside root")
+	}
+}
+
+func TestEnsureWithinRootSymlinkOutside(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	link := filepath.Join(root, "changes")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := ensureWithinRoot(root, link); err == nil {
+		t.Fatal("expected error for symlink pointing outside root")
+	}
+}
+
+func TestEnsureWithinRootSiblingPrefix(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "project")
+	sibling := filepath.Join(parent, "project-other")
+	os.MkdirAll(root, 0755)
+	os.MkdirAll(sibling, 0755)
+
+	if err := ensureWithinRoot(root, sibling); err == nil {
+		t.Fatal("expected error for sibling directory sharing a name prefix")
+	}
+}
+
+func TestEnsureWithinRootMissing(t *testing.T) {
+	root := t.TempDir()
+	if err := ensureWithinRoot(root, filepath.Join(root, "missing")); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+	if err := ensureWithinRoot(filepath.Join(root, "missing"), root); err == nil {
+		t.Fatal("expected error for missing root")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a
+// unified diff, as in diff -u.
+const diffContext = 3
+
+// diffOp is one line of an edit script: kept (' '), removed ('-') or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns a unified diff turning oldText into newText, with
+// headers naming the file as name, or "" when they are equal. An empty
+// oldText is shown as a new file.
+func unifiedDiff(name, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+	if oldText == "" {
+		sb.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&sb, "--- a/%s\n", name)
+	}
+	fmt.Fprintf(&sb, "+++ b/%s\n", name)
+
+	// Line numbers in the old and new text before each op.
+	oldPos, newPos := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if op.kind != '+' {
+			oldPos[i+1]++
+		}
+		if op.kind != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk over later changes separated by at most twice the
+		// context, so their context lines would otherwise overlap.
+		start, end := max(i-diffContext, 0), i+1
+		for j := end; j < len(ops); {
+			if ops[j].kind != ' ' {
+				end, j = j+1, j+1
+				continue
+			}
+			k := j
+			for k < len(ops) && ops[k].kind == ' ' {
+				k++
+			}
+			if k == len(ops) || k-j > 2*diffContext {
+				break
+			}
+			j = k
+		}
+		stop := min(end+diffContext, len(ops))
+
+		oldCount, newCount := oldPos[stop]-oldPos[start], newPos[stop]-newPos[start]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldPos[start], oldCount), hunkRange(newPos[start], newCount))
+		for _, op := range ops[start:stop] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+		i = stop
+	}
+	return sb.String()
+}
+
+// hunkRange formats the start and length of one side of a hunk header. Lines
+// are numbered from one; an empty range names the line before it.
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+// splitLines splits text into lines without their newlines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns a shortest edit script turning a into b, using Myers'
+// algorithm. Release diffs are mostly a section added at the top of a long
+// changelog, which it handles in time proportional to the change.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+	// Find the furthest reaching path for each number of edits d, keeping
+	// the state before every step to walk the path back afterwards.
+search:
+	for d := 0; d <= offset; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{'+', b[y]})
+		} else {
+			x--
+			ops = append(ops, diffOp{'-', a[x]})
+		}
+	}
+	for x > 0 {
+		x--
+		ops = append(ops, diffOp{' ', a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
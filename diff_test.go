@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffEqual(t *testing.T) {
+	if got := unifiedDiff("a.txt", "same\n", "same\n"); got != "" {
+		t.Errorf("expected no diff for equal text, got %q", got)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	got := unifiedDiff("a.txt", "", "one\ntwo\n")
+	want := "--- /dev/null\n+++ b/a.txt\n@@ -0,0 +1,2 @@\n+one\n+two\n"
+	if got != want {
+		t.Errorf("unifiedDiff = %q, expected %q", got, want)
+	}
+}
+
+func TestUnifiedDiffInsertAtTop(t *testing.T) {
+	old := "# Changelog\n\n## v1.0.0\n\n- First\n- Second\n- Third\n- Fourth\n"
+	updated := "# Changelog\n\n## v1.1.0\n\n- New\n\n## v1.0.0\n\n- First\n- Second\n- Third\n- Fourth\n"
+
+	got := unifiedDiff("CHANGELOG.md", old, updated)
+	want := "--- a/CHANGELOG.md\n+++ b/CHANGELOG.md\n" +
+		"@@ -1,5 +1,9 @@\n # Changelog\n \n+## v1.1.0\n+\n+- New\n+\n ## v1.0.0\n \n - First\n"
+	if got != want {
+		t.Errorf("unifiedDiff = %q, expected %q", got, want)
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, string(rune('a'+i)))
+	}
+	old := strings.Join(lines, "\n") + "\n"
+	lines[1], lines[18] = "B", "S"
+	updated := strings.Join(lines, "\n") + "\n"
+
+	got := unifiedDiff("x", old, updated)
+	if strings.Count(got, "@@ -") != 2 {
+		t.Fatalf("expected distant changes in separate hunks, got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n") || !strings.Contains(got, "@@ -16,5 +16,5 @@\n p\n q\n r\n-s\n+S\n t\n") {
+		t.Errorf("unexpected hunks:\n%s", got)
+	}
+
+	lines[1], lines[18] = "b", "s"
+	lines[5], lines[11] = "F", "L"
+	got = unifiedDiff("x", old, strings.Join(lines, "\n")+"\n")
+	if strings.Count(got, "@@ -") != 1 || !strings.Contains(got, "@@ -3,13 +3,13 @@\n") {
+		t.Errorf("expected nearby changes to share a hunk, got:\n%s", got)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	var sb strings.Builder
+	for _, op := range ops {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+		sb.WriteByte(' ')
+	}
+	if got := sb.String(); got != " a -b +x  c +d " {
+		t.Errorf("unexpected edit script %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// cmdDoctor checks the project setup and reports problems. Warnings are
+// printed but only failed checks make the command fail.
+func cmdDoctor(p paths) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	cfg, err := loadValidConfig(p.config)
+	if err != nil {
+		return err
+	}
+	fmt.Println("ok: config.json is valid")
+
+	failed := 0
+
+	_, errs, err := collectChangesets(p.changes, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		fmt.Println("ok: all changesets are valid")
+	}
+	for _, e := range errs {
+		fmt.Printf("error: %s\n", e)
+		failed++
+	}
+
+	directives, err := goDirective(p.root)
+	if err != nil {
+		return err
+	}
+	printGoDirectives(os.Stdout, directives)
+
+	if err := checkGoVersion(os.Stdout, directives.goVersion, cfg.MinGoVersion); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("doctor found %d problems", failed)
+	}
+	return nil
+}
+
+// printGoDirectives reports the Go version and toolchain declared in go.mod.
+func printGoDirectives(w io.Writer, d goModDirectives) {
+	switch {
+	case d.goVersion == "":
+		fmt.Fprintln(w, "warning: go.mod has no go directive")
+	case d.toolchain != "":
+		fmt.Fprintf(w, "ok: go.mod declares go %s with toolchain %s\n", d.goVersion, d.toolchain)
+	default:
+		fmt.Fprintf(w, "ok: go.mod declares go %s\n", d.goVersion)
+	}
+}
+
+// checkGoVersion warns when current, the go.mod "go" directive, is below min.
+// An empty min disables the check.
+func checkGoVersion(w io.Writer, current, min string) error {
+	if min == "" {
+		return nil
+	}
+
+	minVer, err := semver.NewVersion(min)
+	if err != nil {
+		return fmt.Errorf("%w minGoVersion %q: %w", ErrInvalidVersion, min, err)
+	}
+
+	if current == "" {
+		fmt.Fprintf(w, "warning: go.mod has no go directive to compare with the configured minimum %s\n", min)
+		return nil
+	}
+	ver, err := semver.NewVersion(current)
+	if err != nil {
+		return fmt.Errorf("%w go directive %q: %w", ErrInvalidVersion, current, err)
+	}
+
+	if ver.LessThan(minVer) {
+		fmt.Fprintf(w, "warning: go.mod requires go %s, below the configured minimum %s\n", current, min)
+		return nil
+	}
+	fmt.Fprintf(w, "ok: go.mod requires go %s (minimum %s)\n", current, min)
+	return nil
+}
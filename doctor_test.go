@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCmdDoctor(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+
+	output := captureStdout(func() {
+		if err := cmdDoctor(p); err != nil {
+			t.Fatalf("cmdDoctor failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "ok: all changesets are valid") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestCmdDoctorInvalidChangeset(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "no frontmatter")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdDoctor(p)
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid changeset")
+	}
+	if !strings.Contains(output, "error: parse change-0.md") {
+		t.Errorf("expected the invalid changeset to be reported, got:\n%s", output)
+	}
+}
+
+func TestCheckGoVersion(t *testing.T) {
+	var buf bytes.Buffer
+	if err := checkGoVersion(&buf, "1.20", "1.21"); err != nil {
+		t.Fatalf("checkGoVersion failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "warning: go.mod requires go 1.20, below the configured minimum 1.21") {
+		t.Errorf("expected warning, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := checkGoVersion(&buf, "1.20", "1.20.0"); err != nil {
+		t.Fatalf("checkGoVersion failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "ok:") {
+		t.Errorf("expected ok for matching minimum, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := checkGoVersion(&buf, "1.20", ""); err != nil || buf.Len() != 0 {
+		t.Errorf("expected no check without a minimum, got %q (%v)", buf.String(), err)
+	}
+
+	buf.Reset()
+	if err := checkGoVersion(&buf, "", "1.21"); err != nil || !strings.HasPrefix(buf.String(), "warning: go.mod has no go directive") {
+		t.Errorf("expected warning for a missing go directive, got %q (%v)", buf.String(), err)
+	}
+
+	if err := checkGoVersion(&buf, "1.20", "not-a-version"); err == nil {
+		t.Error("expected error for invalid minimum")
+	}
+}
+
+func TestPrintGoDirectives(t *testing.T) {
+	var buf bytes.Buffer
+	printGoDirectives(&buf, goModDirectives{goVersion: "1.25.0", toolchain: "go1.25.1"})
+	if buf.String() != "ok: go.mod declares go 1.25.0 with toolchain go1.25.1\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	printGoDirectives(&buf, goModDirectives{goVersion: "1.25.0"})
+	if buf.String() != "ok: go.mod declares go 1.25.0\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	printGoDirectives(&buf, goModDirectives{})
+	if !strings.HasPrefix(buf.String(), "warning:") {
+		t.Errorf("expected warning without a go directive, got %q", buf.String())
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Sentinel errors for failure modes callers may want to detect with errors.Is.
+var (
+	// ErrNoChangesets is returned when a command needs pending changesets but there are none.
+	ErrNoChangesets = errors.New("no changesets found")
+
+	// ErrNotInitialized is returned when the .changesets directory does not exist.
+	ErrNotInitialized = errors.New(".changesets directory not found")
+
+	// ErrInvalidVersion is returned when a version string is not valid semver.
+	ErrInvalidVersion = errors.New("invalid version")
+
+	// ErrVersionReleased is returned when the changelog already has a section for the version being released.
+	ErrVersionReleased = errors.New("version already in changelog")
+)
+
+// errorCodes maps each sentinel error to the code reported by --json-errors.
+// Errors that wrap none of them get code 1.
+var errorCodes = []struct {
+	err  error
+	code int
+}{
+	{ErrNoChangesets, 2},
+	{ErrNotInitialized, 3},
+	{ErrInvalidVersion, 4},
+	{ErrVersionReleased, 5},
+}
+
+// errorCode returns the --json-errors code for err.
+func errorCode(err error) int {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return 1
+}
+
+// jsonError is the object written to stderr for an error with --json-errors.
+type jsonError struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+}
+
+// printError writes err as an "error: ..." line, or as a JSON object when
+// asJSON is set.
+func printError(w io.Writer, err error, asJSON bool) {
+	if !asJSON {
+		fmt.Fprintf(w, "error: %s\n", err)
+		return
+	}
+
+	if writeErr := writeJSON(w, jsonError{Error: err.Error(), Code: errorCode(err)}, false); writeErr != nil {
+		fmt.Fprintf(w, "error: %s\n", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrNoChangesets(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if !errors.Is(err, ErrNoChangesets) {
+		t.Errorf("expected ErrNoChangesets, got %v", err)
+	}
+}
+
+func TestErrNotInitialized(t *testing.T) {
+	p := newPaths(t.TempDir())
+
+	if err := ensureChangesetsExist(p); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("expected ErrNotInitialized, got %v", err)
+	}
+	if err := cmdNext(p, nextOptions{}); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("expected ErrNotInitialized from cmdNext, got %v", err)
+	}
+}
+
+func TestErrInvalidVersion(t *testing.T) {
+	if _, err := nextVersion("not-a-version", patch); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expected ErrInvalidVersion, got %v", err)
+	}
+
+	p := setupProject(t, "garbage", "---\ntest: patch\n---\n\nFix")
+	if _, _, _, err := calculateNextVersion(p, false, false, time.Time{}); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expected ErrInvalidVersion from calculateNextVersion, got %v", err)
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expected ErrInvalidVersion from cmdRelease, got %v", err)
+	}
+	if err := cmdDoctor(p); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expected ErrInvalidVersion from cmdDoctor, got %v", err)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{fmt.Errorf("%w, nothing to release", ErrNoChangesets), 2},
+		{ErrNotInitialized, 3},
+		{fmt.Errorf("%w: bad", ErrInvalidVersion), 4},
+		{ErrVersionReleased, 5},
+		{errors.New("something else"), 1},
+	}
+
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.expected {
+			t.Errorf("errorCode(%v) = %d, expected %d", tt.err, got, tt.expected)
+		}
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	var buf bytes.Buffer
+	printError(&buf, ErrNoChangesets, false)
+	if buf.String() != "error: no changesets found\n" {
+		t.Errorf("unexpected human-readable error %q", buf.String())
+	}
+
+	buf.Reset()
+	printError(&buf, ErrNoChangesets, true)
+	if buf.String() != `{"error":"no changesets found","code":2}`+"\n" {
+		t.Errorf("unexpected JSON error %q", buf.String())
+	}
+}
+
+func TestExtractGlobalFlag(t *testing.T) {
+	args, found := extractGlobalFlag([]string{"changesets", "--json-errors", "release", "--json"}, "--json-errors")
+	if !found || len(args) != 3 || args[1] != "release" || args[2] != "--json" {
+		t.Errorf("expected the flag to be removed, got %v (%v)", args, found)
+	}
+
+	if _, found := extractGlobalFlag([]string{"changesets", "release"}, "--json-errors"); found {
+		t.Error("expected no flag")
+	}
+
+	args, found = extractGlobalFlag([]string{"changesets", "--json-errors", "--auto-init", "add", "--summary", "--auto-init"}, "--auto-init")
+	if !found || strings.Join(args, " ") != "changesets --json-errors add --summary --auto-init" {
+		t.Errorf("expected only the global flag to be removed, got %v (%v)", args, found)
+	}
+	for _, args := range [][]string{
+		{"changesets", "add", "--summary", "--auto-init"},
+		{"changesets", "--", "--auto-init"},
+	} {
+		if got, found := extractGlobalFlag(args, "--auto-init"); found || len(got) != len(args) {
+			t.Errorf("expected %v to be left alone, got %v (%v)", args, got, found)
+		}
+	}
+}
@@ -1,23 +1,81 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
+// gitEnv names the environment variable that points at the git binary to use.
+const gitEnv = "GIT"
+
+// gitPath is the git binary used when $GIT is unset. Tests override it to
+// simulate a missing or misbehaving git.
+var gitPath = "git"
+
+// gitTimeout bounds every git command; zero disables the limit. run sets it
+// from the gitTimeout config field.
+var gitTimeout = 10 * time.Second
+
+// errGitTimeout is wrapped by the errors of git commands that ran out of time.
+var errGitTimeout = errors.New("git timed out")
+
+// gitTimeoutErr holds the first git timeout of the run. Once git has hung,
+// later commands fail with it straight away instead of each waiting out the
+// timeout.
+var gitTimeoutErr error
+
+// gitCommand returns a command running git with args under ctx, using $GIT if
+// set and gitPath otherwise.
+func gitCommand(ctx context.Context, args ...string) *exec.Cmd {
+	bin := gitPath
+	if env := os.Getenv(gitEnv); env != "" {
+		bin = env
+	}
+	return exec.CommandContext(ctx, bin, args...)
+}
+
+// gitOutput runs git with args and returns its stdout, killing it after
+// gitTimeout. A timeout is reported as an error wrapping errGitTimeout.
+func gitOutput(args ...string) ([]byte, error) {
+	if gitTimeoutErr != nil {
+		return nil, gitTimeoutErr
+	}
+
+	ctx := context.Background()
+	if gitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gitTimeout)
+		defer cancel()
+	}
+
+	cmd := gitCommand(ctx, args...)
+	// Don't wait on children that outlive a killed git and hold its output open.
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		gitTimeoutErr = fmt.Errorf("%w after %s: git %s", errGitTimeout, gitTimeout, strings.Join(args, " "))
+		return nil, gitTimeoutErr
+	}
+	return out, err
+}
+
 // getFileCommitSHA returns the short SHA of the commit that added the given file.
-// It shells out to: git log --diff-filter=A --format=%h -- <filepath>
+// It shells out to: git -C <toplevel> log --diff-filter=A --format=%h -- <filepath>
 // Returns an empty string and nil error if the file is not yet tracked by git.
 // Returns an error if the git command fails for other reasons.
 func getFileCommitSHA(filePath string) (string, error) {
-	cmd := exec.Command("git", "log", "--diff-filter=A", "--format=%h", "--", filePath)
-	out, err := cmd.Output()
+	out, err := gitLogAdded(filePath, "%h")
 	if err != nil {
-		return "", fmt.Errorf("git log failed for %s: %w", filePath, err)
+		return "", err
 	}
 
-	sha := strings.TrimSpace(string(out))
+	sha := strings.TrimSpace(out)
 	if sha == "" {
 		return "", nil
 	}
@@ -30,3 +88,132 @@ func getFileCommitSHA(filePath string) (string, error) {
 	result := strings.TrimSpace(lines[len(lines)-1])
 	return result, nil
 }
+
+// getFileCommitSubject returns the subject line of the commit that added the
+// given file. Returns an empty string and nil error if the file is not yet
+// tracked by git.
+func getFileCommitSubject(filePath string) (string, error) {
+	out, err := gitLogAdded(filePath, "%s")
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	// As with getFileCommitSHA, the last line is the original add.
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// getFileCommitTime returns the committer date of the commit that added the given file.
+// Returns the zero time and nil error if the file is not yet tracked by git.
+func getFileCommitTime(filePath string) (time.Time, error) {
+	out, err := gitLogAdded(filePath, "%cI")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	lines := strings.Fields(out)
+	if len(lines) == 0 {
+		return time.Time{}, nil
+	}
+
+	// As with getFileCommitSHA, the last line is the original add.
+	t, err := time.Parse(time.RFC3339, lines[len(lines)-1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse commit date for %s: %w", filePath, err)
+	}
+	return t, nil
+}
+
+// gitLogAdded runs git log with the given format for the commits that added
+// filePath. It runs in the toplevel of the repository holding the file, with a
+// path relative to it, so lookups work from any working directory and when the
+// module is a subdirectory, worktree, or submodule of the repository.
+func gitLogAdded(filePath, format string) (string, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+	// git reports the toplevel with symlinks resolved, so resolve the
+	// directory too before making the path relative to it.
+	dir := filepath.Dir(abs)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	top, err := gitToplevel(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(top, filepath.Join(dir, filepath.Base(abs)))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+
+	out, err := gitOutput("-C", top, "log", "--diff-filter=A", "--format="+format, "--", filepath.ToSlash(rel))
+	if err != nil {
+		return "", fmt.Errorf("git log failed for %s: %w", filePath, err)
+	}
+	return string(out), nil
+}
+
+// gitToplevel returns the root of the git repository, worktree, or submodule
+// containing dir.
+func gitToplevel(dir string) (string, error) {
+	out, err := gitOutput("-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed for %s: %w", dir, err)
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(out))), nil
+}
+
+// isNotWorkTree reports whether err is git refusing to run because the
+// directory is not inside a git work tree.
+func isNotWorkTree(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	stderr := string(exitErr.Stderr)
+	return strings.Contains(stderr, "not a git repository") || strings.Contains(stderr, "must be run in a work tree")
+}
+
+// gitDirtyFiles returns the absolute paths of the files with uncommitted
+// changes, untracked files included, in the repository containing dir.
+func gitDirtyFiles(dir string) ([]string, error) {
+	top, err := gitToplevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := gitOutput("-C", top, "status", "--porcelain", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+
+	// Entries are "XY path", NUL-terminated; renames and copies are followed
+	// by a second entry holding the original path.
+	var files []string
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, filepath.Join(top, filepath.FromSlash(entry[3:])))
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return files, nil
+}
+
+// gitHead returns the full SHA and subject line of the commit checked out in
+// the repository containing dir.
+func gitHead(dir string) (sha, subject string, err error) {
+	out, err := gitOutput("-C", dir, "log", "-1", "--format=%H%n%s", "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read HEAD in %s: %w", dir, err)
+	}
+	sha, subject, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return sha, subject, nil
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func initTestRepo(t *testing.T) string {
@@ -49,6 +53,118 @@ func TestGetFileCommitSHA(t *testing.T) {
 	}
 }
 
+func TestGetFileCommitSHAFromSubdirectory(t *testing.T) {
+	dir := initTestRepo(t)
+	sub := filepath.Join(dir, "tools")
+	os.MkdirAll(sub, 0755)
+
+	os.WriteFile(filepath.Join(sub, "tracked.txt"), []byte("hello"), 0644)
+	exec.Command("git", "-C", dir, "add", "tools/tracked.txt").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "add tracked file").Run()
+
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+
+	// From the module subdirectory, with a path relative to it, and from
+	// outside the repository entirely, with an absolute path.
+	for _, tt := range []struct{ cwd, path string }{
+		{sub, "tracked.txt"},
+		{t.TempDir(), filepath.Join(sub, "tracked.txt")},
+	} {
+		os.Chdir(tt.cwd)
+		sha, err := getFileCommitSHA(tt.path)
+		if err != nil {
+			t.Fatalf("getFileCommitSHA(%s) from %s failed: %v", tt.path, tt.cwd, err)
+		}
+		if sha == "" {
+			t.Errorf("expected non-empty SHA for %s from %s", tt.path, tt.cwd)
+		}
+	}
+}
+
+func TestCmdReleaseDirtyTree(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", p.root}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init")
+	git("config", "user.email", "nesymno@gmail.com")
+	git("config", "user.name", "nesymno")
+	git("add", ".")
+	git("commit", "-m", "initial")
+
+	// The changeset being released may be uncommitted.
+	os.WriteFile(filepath.Join(p.changes, "fix.md"), []byte("---\ntest: patch\n---\n\nFix"), 0644)
+	os.WriteFile(filepath.Join(p.root, "main.go"), []byte("package main\n"), 0644)
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes (main.go)") || !strings.Contains(err.Error(), "--allow-dirty") {
+		t.Fatalf("expected dirty tree error naming main.go, got %v", err)
+	}
+
+	git("add", "main.go")
+	git("commit", "-m", "add main")
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("expected release with only the changeset uncommitted to succeed, got %v", err)
+	}
+}
+
+func TestCmdReleaseAllowDirty(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	exec.Command("git", "-C", p.root, "init").Run()
+	os.WriteFile(filepath.Join(p.root, "main.go"), []byte("package main\n"), 0644)
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{allowDirty: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease with allowDirty failed: %v", err)
+	}
+}
+
+func TestCmdReleaseDirtyTreeGitFailure(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	exec.Command("git", "-C", p.root, "init").Run()
+	os.WriteFile(filepath.Join(p.root, "other.txt"), []byte("x"), 0644)
+	t.Setenv(gitEnv, filepath.Join(t.TempDir(), "git"))
+
+	err := cmdRelease(p, newScanner(""), releaseOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to check the working tree") {
+		t.Fatalf("expected a failing git to stop the release, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(p.changes, "change-0.md")); statErr != nil {
+		t.Error("the changeset should be kept when the tree cannot be checked")
+	}
+}
+
+func TestCmdReleaseDirtyTreeOutput(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	exec.Command("git", "-C", p.root, "init").Run()
+	os.WriteFile(filepath.Join(p.root, "other.txt"), []byte("x"), 0644)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{output: "-"})
+	})
+	if err != nil {
+		t.Fatalf("expected --output to render notes from a dirty tree, got %v", err)
+	}
+	if !strings.Contains(output, "- Fix") {
+		t.Errorf("expected the rendered notes, got %q", output)
+	}
+}
+
 func TestGetFileCommitSHAUntracked(t *testing.T) {
 	dir := initTestRepo(t)
 
@@ -78,3 +194,150 @@ func TestGetFileCommitSHAGitNotFound(t *testing.T) {
 		t.Fatal("expected error when git is not in PATH, got nil")
 	}
 }
+
+func TestGitCommandOverride(t *testing.T) {
+	orig := gitPath
+	defer func() { gitPath = orig }()
+
+	gitPath = "/nonexistent/git"
+	if _, err := getFileCommitSHA("go.mod"); err == nil {
+		t.Fatal("expected error with gitPath pointing at a missing binary, got nil")
+	}
+
+	t.Setenv("GIT", "/other/git")
+	if cmd := gitCommand(context.Background(), "status"); cmd.Path != "/other/git" {
+		t.Errorf("expected $GIT to take precedence, got %s", cmd.Path)
+	}
+}
+
+func TestGetFileCommitTime(t *testing.T) {
+	dir := initTestRepo(t)
+
+	os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("hello"), 0644)
+	exec.Command("git", "-C", dir, "add", "tracked.txt").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "add tracked file").Run()
+
+	origDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(origDir)
+
+	ts, err := getFileCommitTime("tracked.txt")
+	if err != nil {
+		t.Fatalf("getFileCommitTime failed: %v", err)
+	}
+	if ts.IsZero() {
+		t.Error("expected non-zero time for tracked file")
+	}
+}
+
+func TestGetFileCommitTimeUntracked(t *testing.T) {
+	dir := initTestRepo(t)
+
+	os.WriteFile(filepath.Join(dir, "dummy.txt"), []byte("x"), 0644)
+	exec.Command("git", "-C", dir, "add", "dummy.txt").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "initial").Run()
+
+	origDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(origDir)
+
+	ts, err := getFileCommitTime("nonexistent.txt")
+	if err != nil {
+		t.Fatalf("getFileCommitTime failed: %v", err)
+	}
+	if !ts.IsZero() {
+		t.Errorf("expected zero time for untracked file, got %v", ts)
+	}
+}
+
+func TestGetFileCommitTimeGitNotFound(t *testing.T) {
+	t.Setenv("PATH", "/nonexistent")
+
+	if _, err := getFileCommitTime("go.mod"); err == nil {
+		t.Fatal("expected error when git is not in PATH, got nil")
+	}
+}
+
+func TestGetFileCommitSubject(t *testing.T) {
+	dir := initTestRepo(t)
+
+	os.WriteFile(filepath.Join(dir, "dummy.txt"), []byte("x"), 0644)
+	exec.Command("git", "-C", dir, "add", "dummy.txt").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "initial").Run()
+	os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("hello"), 0644)
+	exec.Command("git", "-C", dir, "add", "tracked.txt").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "Add tracked file\n\nWith a body.").Run()
+
+	subject, err := getFileCommitSubject(filepath.Join(dir, "tracked.txt"))
+	if err != nil {
+		t.Fatalf("getFileCommitSubject failed: %v", err)
+	}
+	if subject != "Add tracked file" {
+		t.Errorf("expected the adding commit's subject, got %q", subject)
+	}
+
+	subject, err = getFileCommitSubject(filepath.Join(dir, "untracked.txt"))
+	if err != nil || subject != "" {
+		t.Errorf("expected no subject for an untracked file, got %q, %v", subject, err)
+	}
+}
+
+// slowGit points gitPath at a stub that hangs, with a short gitTimeout, for
+// the rest of the test.
+func slowGit(t *testing.T) {
+	t.Helper()
+	stub := filepath.Join(t.TempDir(), "git")
+	if err := os.WriteFile(stub, []byte("#!/bin/sh\nexec sleep 5\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	origPath, origTimeout := gitPath, gitTimeout
+	gitPath, gitTimeout = stub, 50*time.Millisecond
+	t.Cleanup(func() {
+		gitPath, gitTimeout, gitTimeoutErr = origPath, origTimeout, nil
+	})
+}
+
+func TestGitOutputTimeout(t *testing.T) {
+	slowGit(t)
+
+	start := time.Now()
+	sha, err := getFileCommitSHA("go.mod")
+	if !errors.Is(err, errGitTimeout) || sha != "" {
+		t.Fatalf("expected a timeout error and no SHA, got %q, %v", sha, err)
+	}
+	if _, err := getFileCommitSHA("go.mod"); !errors.Is(err, errGitTimeout) {
+		t.Errorf("expected later commands to fail with the timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected git to be stopped at the timeout, took %s", elapsed)
+	}
+}
+
+func TestCmdReleaseGitTimeout(t *testing.T) {
+	slowGit(t)
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("expected the release to go ahead without SHAs, got %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(p.root, changelogFile))
+	if !strings.Contains(string(data), "- Fixed bug\n") {
+		t.Errorf("expected an entry without a SHA, got:\n%s", data)
+	}
+
+	gitTimeoutErr = nil
+	p = setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+	saveConfig(p.config, &config{Version: "v1.0.0", FailOnGitTimeout: true})
+	err = cmdRelease(p, newScanner(""), releaseOptions{})
+	if !errors.Is(err, errGitTimeout) {
+		t.Fatalf("expected failOnGitTimeout to fail the release, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(p.root, changelogFile)); !os.IsNotExist(statErr) {
+		t.Error("nothing should be written when the release fails on a timeout")
+	}
+}
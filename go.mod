@@ -2,4 +2,9 @@ module github.com/nesymno/changesets
 
 go 1.25.0
 
-require github.com/Masterminds/semver/v3 v3.4.0
+require (
+	github.com/Masterminds/semver/v3 v3.4.0
+	golang.org/x/term v0.45.0
+)
+
+require golang.org/x/sys v0.47.0 // indirect
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// indexFile is the archive of released changesets in .changesets/, kept by
+// release when maintainIndex is enabled and searched by find.
+const indexFile = "index.json"
+
+// indexEntry records one released changeset.
+type indexEntry struct {
+	Slug    string `json:"slug"`
+	Version string `json:"version"`
+	Package string `json:"package,omitempty"` // workspace package; empty for the root module
+	Bump    string `json:"bump"`
+	Date    string `json:"date"`
+	Summary string `json:"summary"`
+}
+
+// newIndexEntries describes changes released as ver of pkg on date.
+func newIndexEntries(ver, pkg string, date time.Time, changes []*changeset) []indexEntry {
+	if date.IsZero() {
+		date = time.Now()
+	}
+	entries := make([]indexEntry, 0, len(changes))
+	for _, cs := range changes {
+		entries = append(entries, indexEntry{
+			Slug:    cs.slug,
+			Version: ver,
+			Package: pkg,
+			Bump:    string(cs.bump),
+			Date:    date.Format(releaseDateLayout),
+			Summary: cs.summary,
+		})
+	}
+	return entries
+}
+
+// readIndex returns the entries of .changesets/index.json, oldest first, or
+// nil when the index does not exist yet.
+func readIndex(p paths) ([]indexEntry, error) {
+	data, err := os.ReadFile(filepath.Join(p.changesets, indexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexFile, err)
+	}
+
+	var entries []indexEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", indexFile, err)
+	}
+	return entries, nil
+}
+
+// appendIndex adds entries to the end of .changesets/index.json, creating it
+// if needed.
+func appendIndex(p paths, entries []indexEntry) error {
+	existing, err := readIndex(p)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, append(existing, entries...), true); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", indexFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(p.changesets, indexFile), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", indexFile, err)
+	}
+	return nil
+}
+
+// cmdFind prints the released changesets whose slug or summary contains
+// query, compared case-insensitively, newest release first.
+func cmdFind(p paths, args []string) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		return fmt.Errorf("usage: changesets find <query>")
+	}
+
+	entries, err := readIndex(p)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		return fmt.Errorf("no %s found; set maintainIndex in config.json to record released changesets", indexFile)
+	}
+
+	matches := findIndexEntries(entries, args[0])
+	if len(matches) == 0 {
+		fmt.Printf("No released changesets match %q.\n", args[0])
+		return nil
+	}
+	return printIndexEntries(os.Stdout, matches)
+}
+
+// findIndexEntries returns the entries whose slug or summary contains query,
+// ignoring case, newest first.
+func findIndexEntries(entries []indexEntry, query string) []indexEntry {
+	query = strings.ToLower(query)
+	var matches []indexEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if strings.Contains(strings.ToLower(e.Slug), query) || strings.Contains(strings.ToLower(e.Summary), query) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// printIndexEntries writes a table of index entries.
+func printIndexEntries(w io.Writer, entries []indexEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tDATE\tSLUG\tSUMMARY")
+	for _, e := range entries {
+		version := e.Version
+		if e.Package != "" {
+			version = e.Package + "@" + e.Version
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", version, e.Date, e.Slug, firstLine(e.Summary))
+	}
+	return tw.Flush()
+}
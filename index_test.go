@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdReleaseMaintainIndex(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded JSON output")
+	saveConfig(p.config, &config{Version: "v1.0.0", MaintainIndex: true})
+
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{date: "2024-03-01"}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	os.WriteFile(filepath.Join(p.changes, "fix.md"), []byte("---\ntest: patch\n---\n\nFixed the JSON encoder"), 0644)
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{date: "2024-03-08"}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+
+	entries, err := readIndex(p)
+	if err != nil {
+		t.Fatalf("readIndex failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 index entries, got %+v", entries)
+	}
+	want := indexEntry{Slug: "change-0", Version: "v1.1.0", Bump: "minor", Date: "2024-03-01", Summary: "Added JSON output"}
+	if entries[0] != want {
+		t.Errorf("entries[0] = %+v, expected %+v", entries[0], want)
+	}
+	if entries[1].Slug != "fix" || entries[1].Version != "v1.1.1" {
+		t.Errorf("expected the second release appended, got %+v", entries[1])
+	}
+
+	output := captureStdout(func() {
+		if err := cmdFind(p, []string{"json"}); err != nil {
+			t.Fatalf("cmdFind failed: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "v1.1.1") || !strings.Contains(lines[2], "Added JSON output") {
+		t.Errorf("expected both matches, newest first, got:\n%s", output)
+	}
+
+	output = captureStdout(func() {
+		if err := cmdFind(p, []string{"yaml"}); err != nil {
+			t.Fatalf("cmdFind failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `No released changesets match "yaml".`) {
+		t.Errorf("unexpected output %q", output)
+	}
+}
+
+func TestCmdReleaseNoIndex(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(p.changesets, indexFile)); !os.IsNotExist(err) {
+		t.Error("index.json should only be written with maintainIndex")
+	}
+	if err := cmdFind(p, []string{"bug"}); err == nil || !strings.Contains(err.Error(), "maintainIndex") {
+		t.Errorf("expected find to point at maintainIndex, got %v", err)
+	}
+	if err := cmdFind(p, nil); err == nil {
+		t.Error("expected a usage error without a query")
+	}
+}
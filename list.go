@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// listOptions holds the flags accepted by the list command.
+type listOptions struct {
+	sort        string // one of sortBySlug, sortByBump, sortByDate
+	lenientBump bool   // treat unknown bump types as patch
+	width       int    // truncate summaries to this many columns; zero detects the terminal width
+	long        bool   // print full summaries without truncation
+}
+
+const (
+	sortBySlug = "slug"
+	sortByBump = "bump"
+	sortByDate = "date"
+)
+
+// parseListFlags parses the arguments following "list".
+func parseListFlags(args []string) (listOptions, error) {
+	var opts listOptions
+	fs := newFlagSet("list")
+	fs.StringVar(&opts.sort, "sort", sortBySlug, "order changesets by `mode` (bump, slug, or date)")
+	fs.BoolVar(&opts.lenientBump, "lenient-bump", false, "treat unknown bump types as patch instead of failing")
+	fs.IntVar(&opts.width, "width", 0, "truncate summaries to `columns` (default: terminal width)")
+	fs.BoolVar(&opts.long, "long", false, "print full summaries without truncation")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.width < 0 {
+		return opts, fmt.Errorf("invalid width %d, expected a positive number of columns", opts.width)
+	}
+
+	switch opts.sort {
+	case sortBySlug, sortByBump, sortByDate:
+		return opts, nil
+	default:
+		return opts, fmt.Errorf("invalid sort mode %q, expected bump, slug, or date", opts.sort)
+	}
+}
+
+// cmdList prints every pending changeset.
+func cmdList(p paths, opts listOptions) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	parseOpts := cfg.parseOptions()
+	parseOpts.lenientBump = opts.lenientBump
+	changes, err := listChangesets(p.changes, parseOpts)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No pending changesets.")
+		return nil
+	}
+
+	sortChangesets(changes, opts.sort)
+	return printChangesets(os.Stdout, changes, summaryWidth(opts.width, opts.long))
+}
+
+// sortChangesets orders changesets in place according to mode.
+// Bump sorts the most impactful changes first; date sorts the oldest first.
+// Ties are broken by slug so the output is stable.
+func sortChangesets(changes []*changeset, mode string) {
+	var less func(a, b *changeset) bool
+	switch mode {
+	case sortByBump:
+		less = func(a, b *changeset) bool {
+			return bumpPriority(a.bump) > bumpPriority(b.bump)
+		}
+	case sortByDate:
+		times := make(map[*changeset]time.Time, len(changes))
+		for _, cs := range changes {
+			times[cs] = changesetTime(cs)
+		}
+		less = func(a, b *changeset) bool {
+			return times[a].Before(times[b])
+		}
+	default:
+		less = func(a, b *changeset) bool { return false }
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.slug < b.slug
+	})
+}
+
+// changesetTime returns when a changeset was added: the git add date when the
+// file is committed, otherwise its modification time.
+func changesetTime(cs *changeset) time.Time {
+	if t, err := getFileCommitTime(cs.filepath); err == nil && !t.IsZero() {
+		return t
+	}
+	if info, err := os.Stat(cs.filepath); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// printChangesets writes one aligned line per changeset: bump, slug, and the
+// first line of the summary, truncated so the line fits in width columns. A
+// width of zero or less prints the full summary, with later lines indented
+// under the first.
+func printChangesets(w io.Writer, changes []*changeset, width int) error {
+	bumpWidth, slugWidth := 0, 0
+	for _, cs := range changes {
+		bumpWidth = max(bumpWidth, len(cs.bump))
+		slugWidth = max(slugWidth, len(cs.slug))
+	}
+	indent := strings.Repeat(" ", bumpWidth+2+slugWidth+2)
+
+	for _, cs := range changes {
+		first, rest, _ := strings.Cut(cs.summary, "\n")
+		if width > 0 {
+			first, rest = truncate(first, width-len(indent)), ""
+		}
+		if _, err := fmt.Fprintf(w, "%-*s  %-*s  %s\n", bumpWidth, cs.bump, slugWidth, cs.slug, first); err != nil {
+			return err
+		}
+		if rest == "" {
+			continue
+		}
+		for _, line := range strings.Split(rest, "\n") {
+			if line != "" {
+				line = indent + line
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// minSummaryWidth keeps some of every summary visible in narrow terminals.
+const minSummaryWidth = 10
+
+// truncate shortens s to at most n runes, ending it with an ellipsis when
+// anything was cut. n is raised to minSummaryWidth if smaller.
+func truncate(s string, n int) string {
+	n = max(n, minSummaryWidth)
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// defaultTerminalWidth is used when the terminal width cannot be detected.
+const defaultTerminalWidth = 80
+
+// summaryWidth resolves the --width and --long flags to the width passed to
+// printChangesets: zero for full summaries, otherwise the explicit width or
+// the detected terminal width.
+func summaryWidth(width int, long bool) int {
+	if long {
+		return 0
+	}
+	if width > 0 {
+		return width
+	}
+	return terminalWidth()
+}
+
+// terminalWidth returns the width from the COLUMNS environment variable when
+// set, then the size of the terminal attached to stdout, or
+// defaultTerminalWidth otherwise.
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if fd := int(os.Stdout.Fd()); term.IsTerminal(fd) {
+		if n, _, err := term.GetSize(fd); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultTerminalWidth
+}
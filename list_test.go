@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseListFlags(t *testing.T) {
+	opts, err := parseListFlags(nil)
+	if err != nil {
+		t.Fatalf("parseListFlags failed: %v", err)
+	}
+	if opts.sort != sortBySlug {
+		t.Errorf("expected default sort slug, got %q", opts.sort)
+	}
+
+	opts, err = parseListFlags([]string{"--sort", "bump"})
+	if err != nil {
+		t.Fatalf("parseListFlags failed: %v", err)
+	}
+	if opts.sort != sortByBump {
+		t.Errorf("expected sort bump, got %q", opts.sort)
+	}
+}
+
+func TestParseListFlagsInvalidSort(t *testing.T) {
+	if _, err := parseListFlags([]string{"--sort", "size"}); err == nil {
+		t.Fatal("expected error for invalid sort mode")
+	}
+}
+
+func TestParseListFlagsUnknown(t *testing.T) {
+	if _, err := parseListFlags([]string{"--width", "-1"}); err == nil {
+		t.Error("expected error for negative width")
+	}
+
+	if _, err := parseListFlags([]string{"--bogus"}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
+}
+
+func slugs(changes []*changeset) []string {
+	var out []string
+	for _, cs := range changes {
+		out = append(out, cs.slug)
+	}
+	return out
+}
+
+func TestSortChangesetsBySlug(t *testing.T) {
+	changes := []*changeset{
+		{slug: "charlie", bump: major},
+		{slug: "alpha", bump: patch},
+		{slug: "bravo", bump: minor},
+	}
+
+	sortChangesets(changes, sortBySlug)
+
+	if got := strings.Join(slugs(changes), ","); got != "alpha,bravo,charlie" {
+		t.Errorf("expected alpha,bravo,charlie, got %s", got)
+	}
+}
+
+func TestSortChangesetsByBump(t *testing.T) {
+	changes := []*changeset{
+		{slug: "delta", bump: patch},
+		{slug: "alpha", bump: minor},
+		{slug: "charlie", bump: major},
+		{slug: "bravo", bump: patch},
+	}
+
+	sortChangesets(changes, sortByBump)
+
+	if got := strings.Join(slugs(changes), ","); got != "charlie,alpha,bravo,delta" {
+		t.Errorf("expected charlie,alpha,bravo,delta, got %s", got)
+	}
+}
+
+func TestSortChangesetsByDate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	var changes []*changeset
+	for i, name := range []string{"alpha", "bravo", "charlie"} {
+		path := filepath.Join(dir, name+".md")
+		os.WriteFile(path, []byte("x"), 0644)
+		// alpha is newest, charlie is oldest
+		mtime := now.Add(time.Duration(i) * -time.Hour)
+		os.Chtimes(path, mtime, mtime)
+		changes = append(changes, &changeset{filepath: path, slug: name, bump: patch})
+	}
+
+	sortChangesets(changes, sortByDate)
+
+	if got := strings.Join(slugs(changes), ","); got != "charlie,bravo,alpha" {
+		t.Errorf("expected charlie,bravo,alpha, got %s", got)
+	}
+}
+
+func TestPrintChangesetsTruncates(t *testing.T) {
+	changes := []*changeset{
+		{slug: "brave-orange-fox", bump: minor, summary: strings.Repeat("word ", 20) + "\n\nMore details"},
+	}
+
+	var buf bytes.Buffer
+	if err := printChangesets(&buf, changes, 40); err != nil {
+		t.Fatalf("printChangesets failed: %v", err)
+	}
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if n := len([]rune(line)); n != 40 || !strings.HasSuffix(line, "…") {
+		t.Errorf("expected a 40-column line ending in an ellipsis, got %d: %q", n, line)
+	}
+
+	buf.Reset()
+	if err := printChangesets(&buf, changes, 0); err != nil {
+		t.Fatalf("printChangesets failed: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "word \n\n                         More details\n") {
+		t.Errorf("expected the full summary, got %q", buf.String())
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 20); got != "short" {
+		t.Errorf("expected short text unchanged, got %q", got)
+	}
+	if got := truncate("exactly-ten", 11); got != "exactly-ten" {
+		t.Errorf("expected text at the limit unchanged, got %q", got)
+	}
+	if got := truncate("a somewhat longer summary", 2); got != "a somewha…" {
+		t.Errorf("expected truncation to the minimum width, got %q", got)
+	}
+}
+
+func TestSummaryWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "120")
+	if got := summaryWidth(0, false); got != 120 {
+		t.Errorf("expected width from COLUMNS, got %d", got)
+	}
+	if got := summaryWidth(60, false); got != 60 {
+		t.Errorf("expected explicit width, got %d", got)
+	}
+	if got := summaryWidth(60, true); got != 0 {
+		t.Errorf("expected no truncation with --long, got %d", got)
+	}
+
+	t.Setenv("COLUMNS", "")
+	if got := summaryWidth(0, false); got != defaultTerminalWidth {
+		t.Errorf("expected default width, got %d", got)
+	}
+}
+
+func TestChangesetTimeMissingFile(t *testing.T) {
+	cs := &changeset{filepath: "/nonexistent/file.md"}
+	if got := changesetTime(cs); !got.IsZero() {
+		t.Errorf("expected zero time for missing file, got %v", got)
+	}
+}
+
+func TestPrintChangesets(t *testing.T) {
+	changes := []*changeset{
+		{slug: "brave-orange-fox", bump: minor, summary: "Added feature\n\nMore details"},
+	}
+
+	var buf bytes.Buffer
+	if err := printChangesets(&buf, changes, defaultTerminalWidth); err != nil {
+		t.Fatalf("printChangesets failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "minor") || !strings.Contains(output, "brave-orange-fox") {
+		t.Errorf("expected bump and slug in output, got %q", output)
+	}
+	if !strings.Contains(output, "Added feature") || strings.Contains(output, "More details") {
+		t.Errorf("expected only the first summary line, got %q", output)
+	}
+}
+
+func TestCmdList(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"---\ntest: major\n---\n\nBreaking",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdList(p, listOptions{sort: sortByBump})
+	})
+	if err != nil {
+		t.Fatalf("cmdList failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), output)
+	}
+	if !strings.HasPrefix(lines[0], "major") {
+		t.Errorf("expected major changeset first, got %q", lines[0])
+	}
+}
+
+func TestCmdListEmpty(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdList(p, listOptions{sort: sortBySlug})
+	})
+	if err != nil {
+		t.Fatalf("cmdList failed: %v", err)
+	}
+	if !strings.Contains(output, "No pending changesets") {
+		t.Errorf("expected empty message, got %q", output)
+	}
+}
+
+func TestCmdListNoDir(t *testing.T) {
+	p := newPaths(t.TempDir())
+	if err := cmdList(p, listOptions{}); err == nil {
+		t.Fatal("expected error when .changesets doesn't exist")
+	}
+}
+
+func TestCmdListConfigMissing(t *testing.T) {
+	p := newPaths(t.TempDir())
+	os.MkdirAll(p.changes, 0755)
+
+	if err := cmdList(p, listOptions{}); err == nil {
+		t.Fatal("expected error when config is missing")
+	}
+}
+
+func TestCmdListParseError(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "not a changeset")
+
+	var err error
+	captureStdout(func() {
+		err = cmdList(p, listOptions{})
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid changeset")
+	}
+}
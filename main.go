@@ -2,14 +2,17 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
-
-	semver "github.com/Masterminds/semver/v3"
 )
 
 var version = "dev"
@@ -19,6 +22,8 @@ func main() {
 }
 
 func run(args []string, stdin io.Reader) int {
+	args, jsonErrors := extractGlobalFlag(args, "--json-errors")
+	args, autoInitFlag := extractGlobalFlag(args, "--auto-init")
 	if len(args) < 2 {
 		printUsage()
 		return 1
@@ -35,47 +40,480 @@ func run(args []string, stdin io.Reader) int {
 
 	p, err := resolvePaths()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\nAre you inside a Go project?\n", err)
+		if jsonErrors {
+			printError(os.Stderr, err, true)
+		} else {
+			fmt.Fprintf(os.Stderr, "error: %s\nAre you inside a Go project?\n", err)
+		}
 		return 1
 	}
 
-	scanner := bufio.NewScanner(stdin)
+	if cfg, err := loadConfig(p.config); err == nil {
+		// An invalid value is reported by the commands that validate the config.
+		if d, err := cfg.gitTimeout(); err == nil {
+			gitTimeout = d
+		}
+	}
+
+	// init has its own handling of an existing directory.
+	if autoInitFlag && args[1] != "init" {
+		if err := autoInit(p, binaryName(args[0])); err != nil {
+			printError(os.Stderr, err, jsonErrors)
+			return 1
+		}
+	}
+
+	scanner := newInputScanner(stdin)
 
 	switch args[1] {
 	case "init":
-		err = cmdInit(p, scanner)
-	case "add":
-		err = cmdAdd(p, scanner)
+		var opts initOptions
+		if opts, err = parseInitFlags(args[2:]); err == nil {
+			opts.binary = binaryName(args[0])
+			err = cmdInit(p, scanner, opts)
+		}
+	case "add", "new", "create":
+		var opts addOptions
+		if opts, err = parseAddFlags(args[2:]); err == nil {
+			err = cmdAdd(p, scanner, opts)
+		}
 	case "next":
-		err = cmdNext(p)
-	case "release":
-		err = cmdRelease(p)
+		var opts nextOptions
+		if opts, err = parseNextFlags(args[2:]); err == nil {
+			err = cmdNext(p, opts)
+		}
+	case "release", "bump":
+		var opts releaseOptions
+		if opts, err = parseReleaseFlags(args[2:]); err == nil {
+			err = cmdRelease(p, scanner, opts)
+		}
+	case "list":
+		var opts listOptions
+		if opts, err = parseListFlags(args[2:]); err == nil {
+			err = cmdList(p, opts)
+		}
+	case "validate":
+		var opts validateOptions
+		if opts, err = parseValidateFlags(args[2:]); err == nil {
+			err = cmdValidate(p, opts)
+		}
+	case "preview":
+		err = cmdPreview(p, args[2:])
+	case "prune":
+		var opts pruneOptions
+		if opts, err = parsePruneFlags(args[2:]); err == nil {
+			err = cmdPrune(p, scanner, opts)
+		}
+	case "doctor":
+		err = cmdDoctor(p)
+	case "verify-changelog":
+		err = cmdVerifyChangelog(p)
+	case "changelog":
+		var opts changelogRangeOptions
+		if opts, err = parseChangelogFlags(args[2:]); err == nil {
+			err = cmdChangelog(p, opts)
+		}
+	case "stat":
+		var opts statOptions
+		if opts, err = parseStatFlags(args[2:]); err == nil {
+			err = cmdStat(p, opts)
+		}
+	case "amend-version":
+		err = cmdAmendVersion(p, args[2:])
+	case "find":
+		err = cmdFind(p, args[2:])
+	case "tag":
+		var opts tagOptions
+		if opts, err = parseTagFlags(args[2:]); err == nil {
+			err = cmdTag(p, opts)
+		}
+	case "status":
+		var opts statusOptions
+		if opts, err = parseStatusFlags(args[2:]); err == nil {
+			err = cmdStatus(p, opts)
+		}
+	case "config":
+		err = cmdConfig(p, args[2:])
 	default:
+		if jsonErrors {
+			printError(os.Stderr, fmt.Errorf("unknown command: %s", args[1]), true)
+			return 1
+		}
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[1])
 		printUsage()
 		return 1
 	}
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		printError(os.Stderr, err, jsonErrors)
 		return 1
 	}
 
 	return 0
 }
 
+// extractGlobalFlag removes a global boolean flag such as --json-errors from
+// args and reports whether it was present. Only the flags before the command
+// name are searched, and a "--" ends them, so a command's own arguments are
+// never taken for the flag, e.g. in add --summary --auto-init.
+func extractGlobalFlag(args []string, name string) ([]string, bool) {
+	found := false
+	rest := make([]string, 0, len(args))
+	for i, arg := range args {
+		if i == 0 {
+			rest = append(rest, arg)
+			continue
+		}
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if arg == name {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
 func printUsage() {
 	fmt.Println(`changesets - Manage changelogs with semantic versioning
 
 Usage:
-  changesets <command>
+  changesets [--json-errors] <command>
 
 Commands:
   init        Initialize .changesets directory
-  add         Create a new changeset
+  add         Create a new changeset (aliases: new, create)
   next        Calculate and print the next version
+  list        List pending changesets
+  status      Show the current and next version with pending changesets
+  validate    Check every pending changeset and report all invalid ones
+  preview <slug>
+              Print the changelog entry a pending changeset will produce
+  prune       Remove empty or unparseable files from the changes directory
+  doctor      Check the project setup for problems
+  verify-changelog
+              Check that CHANGELOG.md's latest release matches config.json
   release     Bump version, update CHANGELOG.md, and clean up changesets
-  version     Print the CLI version`)
+              (alias: bump)
+  changelog --from <version> --to <version>
+              Print the CHANGELOG.md sections for a range of versions
+  stat        Summarize the release history in CHANGELOG.md
+  amend-version <version>
+              Correct the version of the most recent release
+  find <query>
+              Search the changesets recorded in .changesets/index.json
+  tag --preview
+              Show the commit and tag name the release would be tagged with
+  config      Inspect configuration (subcommands: schema, effective)
+  version     Print the CLI version
+
+Flags:
+  --json-errors
+              Print errors to stderr as JSON: {"error":"...","code":N}
+  --auto-init Create .changesets with the init defaults when it is missing`)
+}
+
+// maxInputLine is the longest line accepted from interactive input, so a long
+// pasted summary is read in full rather than failing at bufio's 64KB default.
+const maxInputLine = defaultMaxChangesetSize
+
+// newInputScanner returns a line scanner for prompts that accepts lines up to
+// maxInputLine bytes.
+func newInputScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxInputLine)
+	return scanner
+}
+
+// newFlagSet returns a flag set for a subcommand that reports parse errors
+// through the returned error instead of printing them.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// initOptions holds the flags accepted by the init command.
+type initOptions struct {
+	noReadme  bool   // skip writing .changesets/README.md
+	noGitkeep bool   // skip writing .changesets/changes/.gitkeep
+	binary    string // command name used in the generated README
+	dryRun    bool   // print what would be created without writing anything
+	flat      bool   // keep changesets directly in .changesets, without a changes subdirectory
+}
+
+// parseInitFlags parses the arguments following "init".
+func parseInitFlags(args []string) (initOptions, error) {
+	var opts initOptions
+	fs := newFlagSet("init")
+	fs.BoolVar(&opts.noReadme, "no-readme", false, "do not write .changesets/README.md")
+	fs.BoolVar(&opts.noGitkeep, "no-gitkeep", false, "do not write .changesets/changes/.gitkeep")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print the paths and version init would create without writing anything")
+	fs.BoolVar(&opts.flat, "flat", false, "keep changesets directly in .changesets instead of a changes subdirectory")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// stringsFlag is a flag.Value that collects every occurrence of a repeated flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// optionalBool is a boolean flag.Value that remembers whether it was given, so
+// an absent flag can fall back to a default decided at run time.
+type optionalBool struct {
+	set   bool
+	value bool
+}
+
+func (b *optionalBool) String() string {
+	return strconv.FormatBool(b.value)
+}
+
+func (b *optionalBool) Set(v string) error {
+	value, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	b.set, b.value = true, value
+	return nil
+}
+
+func (b *optionalBool) IsBoolFlag() bool { return true }
+
+// or returns the flag's value if it was given, and def otherwise.
+func (b optionalBool) or(def bool) bool {
+	if b.set {
+		return b.value
+	}
+	return def
+}
+
+// prettyJSON reports whether JSON output should be indented: as given by the
+// --pretty flag, or when stdout is a terminal and a person is reading it.
+func prettyJSON(pretty optionalBool) bool {
+	return pretty.or(isTerminal(os.Stdout))
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeJSON writes v to w as a single line of JSON, or indented when pretty
+// is set. All JSON output goes through it so every command formats alike.
+func writeJSON(w io.Writer, v any, pretty bool) error {
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
+
+// addOptions holds the flags accepted by the add command.
+type addOptions struct {
+	bump           string   // bump type; prompted for when empty
+	messages       []string // summary paragraphs, like git commit -m; prompted for when empty
+	preview        bool     // show the next version the new changeset would lead to
+	author         string   // credit this person in the changelog instead of the commit author
+	noConfirm      bool     // accept the bump from the .bump file without prompting
+	pkg            string   // workspace package the changeset bumps, instead of the root module
+	allowDuplicate bool     // create the changeset even if a pending one has the same content
+	bumpFromBody   bool     // infer the bump from keywords in the summary, then confirm
+	quiet          bool     // skip the next-steps hint printed after the file is written
+	maxPending     int      // refuse to add once this many changesets are pending; zero means no limit
+	fromLabels     string   // file of pull request labels to derive the bump from
+	summaryStdin   bool     // read the summary from the rest of stdin (--summary -)
+}
+
+// parseAddFlags parses the arguments following "add".
+func parseAddFlags(args []string) (addOptions, error) {
+	var opts addOptions
+	var messages stringsFlag
+	fs := newFlagSet("add")
+	var summary string
+	fs.StringVar(&opts.bump, "bump", "", "bump `type` (patch, minor, major, or none)")
+	fs.StringVar(&opts.bump, "type", "", "bump `type`; same as --bump")
+	fs.Var(&messages, "m", "summary `paragraph`; may be repeated")
+	fs.Var(&messages, "message", "summary `paragraph`; may be repeated")
+	fs.StringVar(&summary, "summary", "", "whole summary `text`, or - to read it from stdin")
+	fs.BoolVar(&opts.preview, "preview-version", false, "show the next version including the new changeset")
+	fs.StringVar(&opts.author, "author", "", "credit `name` for the change in the changelog")
+	fs.BoolVar(&opts.noConfirm, "no-confirm", false, "use the bump from the .bump file without prompting")
+	fs.StringVar(&opts.pkg, "package", "", "bump the named workspace package instead of the root module")
+	fs.BoolVar(&opts.allowDuplicate, "allow-duplicate", false, "create the changeset even if a pending one has the same content")
+	fs.BoolVar(&opts.bumpFromBody, "bump-from-body", false, "infer the bump from keywords in the summary and ask for confirmation")
+	fs.BoolVar(&opts.quiet, "quiet", false, "only report the created file, without next steps")
+	fs.IntVar(&opts.maxPending, "max-pending", 0, "refuse to add a changeset once `n` are pending")
+	fs.StringVar(&opts.fromLabels, "from-labels", "", "derive the bump from the pull request labels listed in `file`")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.fromLabels != "" && (opts.bump != "" || opts.bumpFromBody) {
+		return opts, fmt.Errorf("--from-labels cannot be combined with --bump or --bump-from-body")
+	}
+	if opts.maxPending < 0 {
+		return opts, fmt.Errorf("invalid --max-pending %d, expected a positive number", opts.maxPending)
+	}
+	opts.messages = messages
+	if summary != "" {
+		if len(messages) > 0 {
+			return opts, fmt.Errorf("--summary cannot be combined with -m")
+		}
+		if summary == "-" {
+			// Stdin holds the summary, so nothing is left to answer prompts.
+			if opts.bumpFromBody || (opts.bump == "" && opts.fromLabels == "" && !opts.noConfirm) {
+				return opts, fmt.Errorf("--summary - needs the bump from --type, --from-labels or --no-confirm")
+			}
+			opts.summaryStdin = true
+		} else {
+			opts.messages = []string{summary}
+		}
+	}
+	return opts, nil
+}
+
+// nextOptions holds the flags accepted by the next command.
+type nextOptions struct {
+	dir                 string // read changesets from this directory instead of .changesets/changes
+	pkg                 string // print the next version of this workspace package
+	lenientBump         bool   // treat unknown bump types as patch
+	allowPrereleaseBump bool   // advance the prerelease counter for every bump
+}
+
+// parseNextFlags parses the arguments following "next".
+func parseNextFlags(args []string) (nextOptions, error) {
+	var opts nextOptions
+	fs := newFlagSet("next")
+	fs.StringVar(&opts.dir, "dir", "", "read changesets from `directory`")
+	fs.StringVar(&opts.pkg, "package", "", "print the next version of the named workspace package")
+	fs.BoolVar(&opts.lenientBump, "lenient-bump", false, "treat unknown bump types as patch instead of failing")
+	fs.BoolVar(&opts.allowPrereleaseBump, "allow-prerelease-bump", false, "advance the prerelease counter instead of finalizing for minor and major changesets")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// releaseOptions holds the flags accepted by the release command.
+type releaseOptions struct {
+	json                bool         // print a JSON summary instead of the bare version
+	interactive         bool         // preview the release and ask for confirmation before writing
+	pkg                 string       // release only this workspace package
+	replace             bool         // overwrite an existing changelog section for the same version
+	templateFile        string       // render the changelog section with this template file
+	output              string       // only render the section here ("-" for stdout), releasing nothing
+	lenientBump         bool         // treat unknown bump types as patch
+	date                string       // release date (YYYY-MM-DD) for the changelog header; empty means today
+	all                 bool         // release every workspace package with pending changesets
+	keep                bool         // leave consumed changeset files in place
+	pretty              optionalBool // indent --json output; defaults to whether stdout is a terminal
+	quiet               bool         // succeed silently when nothing is pending and skip informational messages
+	channel             string       // release on this prerelease channel; empty means stable
+	allowDirty          bool         // release even if the git working tree has uncommitted changes
+	allowPrereleaseBump bool         // advance the prerelease counter for every bump
+	manifest            bool         // write .changesets/releases/<version>.json listing the consumed changesets
+	allowEmpty          bool         // succeed without releasing when only none changesets are pending
+	version             string       // release as this version instead of the computed one
+	dryRun              bool         // print the next version and changelog section without writing anything
+	diff                bool         // with dryRun, print unified diffs of CHANGELOG.md and config.json instead
+}
+
+// releaseDateLayout is the format of the release --date flag and of the date
+// in changelog headers.
+const releaseDateLayout = "2006-01-02"
+
+// parseReleaseFlags parses the arguments following "release".
+func parseReleaseFlags(args []string) (releaseOptions, error) {
+	var opts releaseOptions
+	fs := newFlagSet("release")
+	fs.BoolVar(&opts.json, "json", false, "print a JSON summary of the release")
+	fs.BoolVar(&opts.interactive, "interactive", false, "preview the release and confirm before writing")
+	fs.StringVar(&opts.pkg, "package", "", "release only the named workspace package")
+	fs.BoolVar(&opts.replace, "replace", false, "overwrite an existing changelog section for the same version")
+	fs.StringVar(&opts.templateFile, "template-file", "", "render the changelog section with the template in `file`")
+	fs.StringVar(&opts.output, "output", "", "only render the changelog section to `file` (- for stdout, clipboard for the clipboard), without releasing")
+	fs.BoolVar(&opts.lenientBump, "lenient-bump", false, "treat unknown bump types as patch instead of failing")
+	fs.StringVar(&opts.date, "date", "", "use `YYYY-MM-DD` as the release date instead of today")
+	fs.BoolVar(&opts.all, "all", false, "release every workspace package with pending changesets")
+	fs.BoolVar(&opts.keep, "keep", false, "keep the consumed changeset files instead of removing them")
+	fs.BoolVar(&opts.keep, "no-clean", false, "alias for --keep")
+	fs.Var(&opts.pretty, "pretty", "indent JSON output (default: when stdout is a terminal)")
+	fs.BoolVar(&opts.quiet, "quiet", false, "exit successfully without output when there is nothing to release")
+	fs.BoolVar(&opts.allowDirty, "allow-dirty", false, "release even if the git working tree has uncommitted changes")
+	fs.StringVar(&opts.channel, "channel", "", "release on the named prerelease `channel` (default stable)")
+	fs.BoolVar(&opts.allowPrereleaseBump, "allow-prerelease-bump", false, "advance the prerelease counter instead of finalizing for minor and major changesets")
+	fs.BoolVar(&opts.manifest, "manifest", false, "write a JSON manifest of the consumed changesets to .changesets/releases/")
+	fs.BoolVar(&opts.allowEmpty, "allow-empty", false, "exit successfully without releasing when only none changesets are pending")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print the next version and changelog section without writing anything")
+	fs.BoolVar(&opts.diff, "diff", false, "with --dry-run, print unified diffs of CHANGELOG.md and config.json")
+	fs.StringVar(&opts.version, "version", "", "release as `version` instead of the computed next version (overrides $"+releaseVersionEnv+")")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	if opts.channel == stableChannel {
+		opts.channel = ""
+	}
+	if opts.channel != "" {
+		if err := validateChannel(opts.channel); err != nil {
+			return opts, err
+		}
+		if opts.pkg != "" || opts.all {
+			return opts, fmt.Errorf("--channel cannot be combined with --package or --all")
+		}
+	}
+
+	if opts.date != "" {
+		if _, err := time.Parse(releaseDateLayout, opts.date); err != nil {
+			return opts, fmt.Errorf("invalid release date %q, expected YYYY-MM-DD", opts.date)
+		}
+	}
+	if opts.all && opts.pkg != "" {
+		return opts, fmt.Errorf("--all cannot be combined with --package")
+	}
+	if opts.manifest && (opts.all || opts.pkg != "") {
+		return opts, fmt.Errorf("--manifest cannot be combined with --package or --all")
+	}
+	if opts.all && opts.output != "" {
+		return opts, fmt.Errorf("--all cannot be combined with --output")
+	}
+	if opts.diff && !opts.dryRun {
+		return opts, fmt.Errorf("--diff requires --dry-run")
+	}
+	if opts.dryRun && (opts.all || opts.pkg != "" || opts.channel != "" || opts.output != "" || opts.interactive) {
+		return opts, fmt.Errorf("--dry-run cannot be combined with --package, --all, --channel, --output or --interactive")
+	}
+	if opts.manifest && opts.output != "" {
+		return opts, fmt.Errorf("--manifest cannot be combined with --output, which does not consume changesets")
+	}
+	if opts.json && opts.output == "-" {
+		return opts, fmt.Errorf("--json cannot be combined with --output -")
+	}
+	return opts, nil
+}
+
+// binaryName returns the command name the CLI was invoked as, so generated docs
+// match how users actually run it. It falls back to "changesets".
+func binaryName(arg0 string) string {
+	name := strings.TrimSuffix(filepath.Base(arg0), ".exe")
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return "changesets"
+	}
+	return name
 }
 
 func resolvePaths() (paths, error) {
@@ -84,11 +522,22 @@ func resolvePaths() (paths, error) {
 		return paths{}, err
 	}
 
-	return newPaths(root), nil
+	p := newPaths(root)
+	if cfg, err := loadConfig(p.config); err == nil && cfg.FlatLayout {
+		p = p.flat()
+	}
+	return p, nil
 }
 
 // cmdInit creates the .changesets directory structure.
-func cmdInit(p paths, scanner *bufio.Scanner) error {
+func cmdInit(p paths, scanner *bufio.Scanner, opts initOptions) error {
+	if opts.flat {
+		p = p.flat()
+	}
+	if opts.dryRun {
+		return printInitPlan(os.Stdout, p, opts)
+	}
+
 	// Check if .changesets already exists
 	if _, err := os.Stat(p.changesets); err == nil {
 		fmt.Print(".changesets already exists. Recreate? (y/n): ")
@@ -107,109 +556,311 @@ func cmdInit(p paths, scanner *bufio.Scanner) error {
 		}
 	}
 
+	if err := createChangesetsDir(p, opts); err != nil {
+		return err
+	}
+
+	fmt.Println("Initialized .changesets directory.")
+	return nil
+}
+
+// createChangesetsDir writes a fresh .changesets directory: the changes
+// directory, config.json seeded with the workspace packages, and unless opts
+// says otherwise the README and .gitkeep.
+func createChangesetsDir(p paths, opts initOptions) error {
 	// Create directories
 	if err := os.MkdirAll(p.changes, 0755); err != nil {
 		return fmt.Errorf("failed to create changes directory: %w", err)
 	}
 
 	// Write config.json
-	cfg := &config{Version: "v0.0.0"}
+	packages, err := seedPackages(p.root)
+	if err != nil {
+		return err
+	}
+	cfg := &config{Version: "v0.0.0", FlatLayout: opts.flat, Packages: packages}
 	if err := saveConfig(p.config, cfg); err != nil {
 		return err
 	}
 
 	// Write README.md
+	if !opts.noReadme {
+		if err := writeInitReadme(p, opts.binary); err != nil {
+			return err
+		}
+	}
+
+	// Write .gitkeep
+	if !opts.noGitkeep && p.gitkeep != "" {
+		if err := os.WriteFile(p.gitkeep, []byte(""), 0644); err != nil {
+			return fmt.Errorf("failed to write .gitkeep: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// autoInit creates the .changesets directory with the defaults of a plain
+// init when it does not exist yet, for the global --auto-init flag. An
+// existing directory is left alone, so it is safe to run before any command.
+func autoInit(p paths, binary string) error {
+	if _, err := os.Stat(p.changesets); err == nil {
+		return nil
+	}
+	return createChangesetsDir(p, initOptions{binary: binary})
+}
+
+// printInitPlan writes the paths init would create and the initial config,
+// so the resolved project root can be checked before anything is written.
+func printInitPlan(w io.Writer, p paths, opts initOptions) error {
+	packages, err := seedPackages(p.root)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Project root: %s\n", p.root)
+	if _, err := os.Stat(p.changesets); err == nil {
+		fmt.Fprintf(w, "%s already exists and would be recreated after confirmation.\n", p.changesets)
+	}
+	fmt.Fprintln(w, "Would create:")
+	created := []string{p.changesets}
+	if p.changes != p.changesets {
+		created = append(created, p.changes)
+	}
+	created = append(created, p.config)
+	if !opts.noReadme {
+		created = append(created, p.readme)
+	}
+	if !opts.noGitkeep && p.gitkeep != "" {
+		created = append(created, p.gitkeep)
+	}
+	for _, path := range created {
+		fmt.Fprintf(w, "  %s\n", path)
+	}
+
+	fmt.Fprintln(w, "Initial version: v0.0.0")
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "Initial version of %s: %s\n", name, packages[name])
+	}
+	return nil
+}
+
+// writeInitReadme writes the contributor guide into .changesets/README.md,
+// referring to the CLI by the given binary name.
+func writeInitReadme(p paths, binary string) error {
+	if binary == "" {
+		binary = "changesets"
+	}
+
 	readme := `# Changesets
 
-This directory is used by [go-changesets](https://github.com/nesymno/go-changesets) to manage versioning and changelogs.
+This directory is used by [changesets](https://github.com/nesymno/changesets) to manage versioning and changelogs.
 
 ## How to add a changeset
 
-Run ` + "`changesets add`" + ` to create a new changeset file describing your change.
+Run ` + "`" + binary + " add`" + ` to create a new changeset file describing your change.
 
 ## How to release
 
-Run ` + "`changesets release`" + ` to bump the version, update CHANGELOG.md, and clean up changeset files.
+Run ` + "`" + binary + " release`" + ` to bump the version, update CHANGELOG.md, and clean up changeset files.
 `
 	if err := os.WriteFile(p.readme, []byte(readme), 0644); err != nil {
 		return fmt.Errorf("failed to write README.md: %w", err)
 	}
 
-	// Write .gitkeep
-	if err := os.WriteFile(p.gitkeep, []byte(""), 0644); err != nil {
-		return fmt.Errorf("failed to write .gitkeep: %w", err)
-	}
-
-	fmt.Println("Initialized .changesets directory.")
 	return nil
 }
 
-// cmdAdd interactively creates a new changeset file.
-func cmdAdd(p paths, scanner *bufio.Scanner) error {
+// cmdAdd creates a new changeset file. Values not provided via flags are prompted
+// for interactively; when both the bump and the summary are given, no prompts are shown.
+func cmdAdd(p paths, scanner *bufio.Scanner, opts addOptions) error {
 	if err := ensureChangesetsExist(p); err != nil {
 		return err
 	}
 
-	repoName, err := moduleName(p.root)
+	cfg, err := loadConfig(p.config)
 	if err != nil {
 		return err
 	}
+	if err := checkPendingLimit(os.Stderr, p, cfg, opts.maxPending); err != nil {
+		return err
+	}
+	sep := cfg.withDefaults().SlugSeparator
+	if err := validateSlugSeparator(sep); err != nil {
+		return err
+	}
+
+	var repoName, slugPrefix string
+	if opts.pkg != "" {
+		packages, err := workspacePackages(p.root)
+		if err != nil {
+			return err
+		}
+		if _, ok := packages[opts.pkg]; !ok {
+			return fmt.Errorf("package %s is not a member of %s", opts.pkg, workspaceFile)
+		}
+		repoName = opts.pkg
+		if cfg.ScopedSlugs {
+			slugPrefix = sanitizeSlugPrefix(opts.pkg, sep)
+		}
+	} else if cfg.UseFullModulePath {
+		if repoName, err = readModulePath(p.root); err != nil {
+			return err
+		}
+	} else if repoName, err = moduleName(p.root); err != nil {
+		return err
+	}
+
+	// 1. Select bump type, defaulting to the branch's .bump file if present.
+	// The file is only read when no flag gives the bump.
+	var defaultBump bumpType
+	if opts.bump == "" && !opts.bumpFromBody && opts.fromLabels == "" {
+		if defaultBump, err = readBumpFile(p.root); err != nil {
+			return err
+		}
+		if opts.summaryStdin && defaultBump == "" {
+			return fmt.Errorf("--summary - with --no-confirm needs a %s file, since stdin cannot also answer the bump prompt; pass --type instead", bumpFile)
+		}
+	}
 
-	// 1. Select bump type
-	fmt.Println("What kind of change is this?")
-	fmt.Println("  1) patch")
-	fmt.Println("  2) minor")
-	fmt.Println("  3) major")
-	fmt.Print("Select [1/2/3]: ")
+	if opts.summaryStdin {
+		summary, err := readAllInput(scanner)
+		if err != nil {
+			return err
+		}
+		opts.messages = []string{summary}
+	}
 
 	var bump bumpType
-	if !scanner.Scan() {
-		return fmt.Errorf("no input received")
-	}
-	choice := strings.TrimSpace(scanner.Text())
-	switch choice {
-	case "1", "patch":
-		bump = patch
-	case "2", "minor":
-		bump = minor
-	case "3", "major":
-		bump = major
+	var summary string
+	bumpGiven, summaryRead := true, false
+	switch {
+	case opts.bump != "":
+		if bump, err = parseBumpType(opts.bump); err != nil {
+			return err
+		}
+	case opts.bumpFromBody:
+		keywords, err := cfg.bumpKeywords()
+		if err != nil {
+			return err
+		}
+		if summary, err = readSummary(scanner, opts.messages); err != nil {
+			return err
+		}
+		summaryRead = true
+		bump, bumpGiven = inferBump(summary, keywords), false
+		fmt.Printf("Inferred a %s bump from the summary.\n", bump)
+	case opts.fromLabels != "":
+		if bump, err = bumpFromLabelsFile(cfg, opts.fromLabels); err != nil {
+			return err
+		}
+	case defaultBump != "" && opts.noConfirm:
+		bump = defaultBump
 	default:
-		return fmt.Errorf("invalid selection: %q", choice)
+		bumpGiven = false
+		fmt.Println("What kind of change is this?")
+		fmt.Println("  1) patch")
+		fmt.Println("  2) minor")
+		fmt.Println("  3) major")
+		fmt.Println("  4) none (changelog note only)")
+		if defaultBump != "" {
+			fmt.Printf("Select [1/2/3/4] (default %s): ", defaultBump)
+		} else {
+			fmt.Print("Select [1/2/3/4]: ")
+		}
+
+		if !scanner.Scan() {
+			return fmt.Errorf("no input received")
+		}
+		choice := strings.TrimSpace(scanner.Text())
+		if choice == "" {
+			choice = string(defaultBump)
+		}
+		switch choice {
+		case "1", "patch":
+			bump = patch
+		case "2", "minor":
+			bump = minor
+		case "3", "major":
+			bump = major
+		case "4", "none":
+			bump = none
+		default:
+			return fmt.Errorf("invalid selection: %q", choice)
+		}
 	}
 
-	// 2. Enter summary
-	fmt.Print("Summary: ")
-	if !scanner.Scan() {
-		return fmt.Errorf("no input received")
+	if opts.preview {
+		nextVer, err := previewNextVersion(p, bump)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("This will contribute a %s; with current pending changesets the next version would be %s.\n", bump, nextVer)
 	}
-	summary := strings.TrimSpace(scanner.Text())
-	if summary == "" {
-		return fmt.Errorf("summary cannot be empty")
+
+	// 2. Enter summary
+	if !summaryRead {
+		if summary, err = readSummary(scanner, opts.messages); err != nil {
+			return err
+		}
 	}
 
-	// 3. Preview and confirm
-	content := changesetContent(repoName, bump, summary)
-	fmt.Println()
-	fmt.Println("--- Preview ---")
-	fmt.Println()
-	fmt.Print(content)
-	fmt.Println()
-	fmt.Println("--- End Preview ---")
-	fmt.Println()
-	fmt.Print("Confirm? (y/n): ")
+	cs := &changeset{
+		releases: []release{{name: repoName, bump: bump}},
+		author:   strings.TrimSpace(opts.author),
+		summary:  summary,
+	}
+	if err := cs.Validate(); err != nil {
+		return err
+	}
+	summaryRe, err := cfg.summaryPattern()
+	if err != nil {
+		return err
+	}
+	if err := checkSummary(summaryRe, cs.summary); err != nil {
+		return err
+	}
 
-	if !scanner.Scan() {
-		return fmt.Errorf("no input received")
+	if !opts.allowDuplicate {
+		// Invalid files are reported by validate; they cannot be duplicates.
+		existing, _, err := collectChangesets(p.changes, cfg.parseOptions())
+		if err != nil {
+			return err
+		}
+		if dup := findDuplicate(existing, cs); dup != nil {
+			return fmt.Errorf("changeset %s already has the same content; pass --allow-duplicate to add it anyway", slugToFilename(dup.slug))
+		}
 	}
-	confirm := strings.TrimSpace(scanner.Text())
-	if !strings.EqualFold(confirm, "y") {
-		fmt.Println("Aborted.")
-		return nil
+
+	// 3. Preview and confirm, unless everything was given on the command line
+	content := cs.content()
+	if !bumpGiven || len(opts.messages) == 0 {
+		fmt.Println()
+		fmt.Println("--- Preview ---")
+		fmt.Println()
+		fmt.Print(content)
+		fmt.Println()
+		fmt.Println("--- End Preview ---")
+		fmt.Println()
+		fmt.Print("Confirm? (y/n): ")
+
+		if !scanner.Scan() {
+			return fmt.Errorf("no input received")
+		}
+		confirm := strings.TrimSpace(scanner.Text())
+		if !strings.EqualFold(confirm, "y") {
+			fmt.Println("Aborted.")
+			return nil
+		}
 	}
 
 	// 4. Generate slug and write file
-	slug, err := generateSlug(p.changes)
+	slug, err := generateSlug(p.changes, sep, slugPrefix)
 	if err != nil {
 		return err
 	}
@@ -221,48 +872,274 @@ func cmdAdd(p paths, scanner *bufio.Scanner) error {
 		return fmt.Errorf("failed to write changeset file: %w", err)
 	}
 
-	fmt.Printf("Created changeset: .changesets/changes/%s\n", filename)
+	relPath, err := filepath.Rel(p.root, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+	fmt.Printf("Created changeset: %s\n", filepath.ToSlash(relPath))
+
+	if !opts.quiet {
+		printAddNextSteps(os.Stdout, p, opts.pkg, filepath.ToSlash(relPath))
+	}
 	return nil
 }
 
+// bumpFromLabelsFile derives a bump from the pull request labels in path
+// using the bumpLabels mapping, failing when no label maps to a bump.
+func bumpFromLabelsFile(cfg *config, path string) (bumpType, error) {
+	mapping, err := cfg.bumpLabels()
+	if err != nil {
+		return "", err
+	}
+	labels, err := readLabels(path)
+	if err != nil {
+		return "", err
+	}
+
+	bump, ok := bumpFromLabels(labels, mapping)
+	if !ok {
+		known := make([]string, 0, len(mapping))
+		for label := range mapping {
+			known = append(known, label)
+		}
+		sort.Strings(known)
+		return "", fmt.Errorf("no label in %s maps to a bump (got %q); expected one of: %s", path, labels, strings.Join(known, ", "))
+	}
+	return bump, nil
+}
+
+// checkPendingLimit counts the pending changesets, valid or not, before add
+// creates another. Reaching the --max-pending limit is an error; reaching the
+// maxPending config limit only prints a warning to w. Without either there is
+// no limit.
+func checkPendingLimit(w io.Writer, p paths, cfg *config, maxPending int) error {
+	limit := maxPending
+	if limit == 0 {
+		limit = cfg.MaxPending
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	changes, errs, err := collectChangesets(p.changes, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+	pending := len(changes) + len(errs)
+	if pending < limit {
+		return nil
+	}
+
+	const msg = "%d changesets are already pending (limit %d); consider running `changesets release` first"
+	if maxPending > 0 {
+		return fmt.Errorf(msg, pending, limit)
+	}
+	fmt.Fprintf(w, "warning: "+msg+"\n", pending, limit)
+	return nil
+}
+
+// printAddNextSteps tells the contributor what the new changeset means: the
+// version a release would produce now, and that the file must be committed.
+// The version is left out if it cannot be computed, since the file was
+// already written.
+func printAddNextSteps(w io.Writer, p paths, pkg, relPath string) {
+	nextVer, changes, cfg, err := calculateNextVersion(p, false, false, time.Time{})
+	if err == nil && pkg != "" {
+		nextVer, err = nextPackageVersion(cfg, changes, pkg, false, time.Time{})
+	}
+	if err == nil {
+		fmt.Fprintf(w, "Releasing now would produce %s.\n", nextVer)
+	}
+	fmt.Fprintf(w, "Remember to commit %s with your change.\n", relPath)
+}
+
+// readSummary joins the -m paragraphs into a summary, or prompts for one
+// when none were given.
+func readSummary(scanner *bufio.Scanner, messages []string) (string, error) {
+	if len(messages) > 0 {
+		paragraphs := make([]string, 0, len(messages))
+		for _, m := range messages {
+			paragraphs = append(paragraphs, strings.TrimSpace(m))
+		}
+		return strings.TrimSpace(strings.Join(paragraphs, "\n\n")), nil
+	}
+
+	fmt.Print("Summary: ")
+	if !scanner.Scan() {
+		return "", fmt.Errorf("no input received")
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+// readAllInput returns the rest of the input with surrounding whitespace
+// trimmed, keeping its line breaks so multi-line summaries survive.
+func readAllInput(scanner *bufio.Scanner) (string, error) {
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read summary: %w", err)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
 // cmdNext calculates and prints the next version.
-func cmdNext(p paths) error {
+func cmdNext(p paths, opts nextOptions) error {
 	if err := ensureChangesetsExist(p); err != nil {
 		return err
 	}
 
-	nextVer, _, _, err := calculateNextVersion(p)
+	if opts.dir != "" {
+		dir, err := filepath.Abs(opts.dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", opts.dir, err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("changesets directory %s not found", opts.dir)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", opts.dir)
+		}
+		p.changes = dir
+	}
+
+	nextVer, changes, cfg, err := calculateNextVersion(p, opts.lenientBump, opts.allowPrereleaseBump, time.Time{})
 	if err != nil {
 		return err
 	}
 
+	if opts.pkg != "" {
+		if nextVer, err = nextPackageVersion(cfg, changes, opts.pkg, opts.allowPrereleaseBump, time.Time{}); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println(nextVer)
 	return nil
 }
 
+// releaseResult is the machine-readable summary printed by `release --json`.
+type releaseResult struct {
+	Package       string `json:"package,omitempty"` // workspace package; empty for the root module
+	Channel       string `json:"channel,omitempty"` // prerelease channel; empty for stable
+	Previous      string `json:"previous"`
+	Version       string `json:"version"`
+	ChangelogPath string `json:"changelogPath"`
+	Consumed      int    `json:"consumed"`
+	Manifest      string `json:"manifest,omitempty"` // release manifest written with --manifest
+}
+
 // cmdRelease bumps the version, updates CHANGELOG.md, and cleans up changesets.
-func cmdRelease(p paths) error {
+func cmdRelease(p paths, scanner *bufio.Scanner, opts releaseOptions) error {
 	if err := ensureChangesetsExist(p); err != nil {
 		return err
 	}
 
-	nextVerStr, changes, cfg, err := calculateNextVersion(p)
+	// Report a corrupt config before anything is written.
+	cfg, err := loadValidConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to delete anything outside the project.
+	if err := ensureWithinRoot(p.root, p.changes); err != nil {
+		return err
+	}
+
+	if !opts.allowDirty && !opts.dryRun && opts.output == "" {
+		if err := ensureCleanTree(p, cfg); err != nil {
+			return err
+		}
+	}
+
+	override, err := releaseVersionOverride(opts.version)
+	if err != nil {
+		return err
+	}
+	if override != "" && (opts.all || opts.pkg != "" || opts.channel != "") {
+		return fmt.Errorf("--version and %s cannot be combined with --package, --all or --channel", releaseVersionEnv)
+	}
+
+	if opts.all {
+		return releaseAllPackages(p, scanner, opts)
+	}
+	if opts.pkg != "" {
+		return releasePackage(p, scanner, opts)
+	}
+	if opts.channel != "" {
+		return releaseChannel(p, scanner, opts)
+	}
+
+	date, _ := time.Parse(releaseDateLayout, opts.date) // validated by parseReleaseFlags
+	nextVerStr, changes, cfg, err := calculateNextVersion(p, opts.lenientBump, opts.allowPrereleaseBump, date)
 	if err != nil {
 		return err
 	}
 
 	if len(changes) == 0 {
-		return fmt.Errorf("no changesets found, nothing to release")
+		if opts.quiet {
+			return nil
+		}
+		return noChangesetsError(p.changes)
+	}
+	if override != "" {
+		nextVerStr = override
+	} else if highestBump(changes) == none {
+		return noVersionChange(opts)
 	}
 
+	if cfg.WarnPatchOnly && highestBump(changes) == patch {
+		printPatchOnlyWarning(os.Stderr, filepath.Join(p.root, changelogFile), time.Now())
+	}
+	printDroppedSummaries(os.Stderr, changes, cfg.SummaryRender)
+
+	// Remember the version being replaced so compare links can be built from config alone
+	cfg.PreviousVersion = cfg.Version
+
 	// Build changelog section
-	changelogSection := buildChangelogSection(nextVerStr, changes)
+	changelogOpts := cfg.changelogOptions()
+	changelogOpts.date = date
+	if changelogOpts.template, err = loadChangelogTemplate(p.root, cfg, opts.templateFile); err != nil {
+		return err
+	}
+	changelogSection, err := buildChangelogSection(nextVerStr, changes, changelogOpts)
+	if err != nil {
+		return err
+	}
+	if err := checkGitTimeout(os.Stderr, cfg); err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		return printReleaseDryRun(os.Stdout, p, cfg, nextVerStr, changelogSection, changelogOpts, opts)
+	}
+
+	if opts.interactive {
+		ok, err := confirmRelease(scanner, nextVerStr, changelogSection)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	previous := cfg.PreviousVersion
+	if opts.output != "" {
+		return renderReleaseNotes(releaseResult{Previous: previous, Version: nextVerStr}, changelogSection, opts)
+	}
 
 	// Update CHANGELOG.md
-	changelogPath := filepath.Join(p.root, "CHANGELOG.md")
-	if err := prependChangelog(changelogPath, changelogSection); err != nil {
+	changelogPath := filepath.Join(p.root, changelogFile)
+	if err := prependChangelog(changelogPath, changelogSection, cfg.changelogHeading(), opts.replace, cfg.MaxChangelogSections); err != nil {
 		return err
 	}
+	if changelogOpts.compareLinks {
+		if link := compareLink(changelogOpts.repoURL, changelogOpts.previousVersion, nextVerStr); link != "" {
+			if err := addCompareLink(changelogPath, link); err != nil {
+				return err
+			}
+		}
+	}
 
 	// Update config.json
 	cfg.Version = nextVerStr
@@ -270,23 +1147,336 @@ func cmdRelease(p paths) error {
 		return err
 	}
 
-	// Clean up changeset files
-	if err := cleanupChanges(p.changes); err != nil {
+	if err := appendStepSummary(changelogSection); err != nil {
 		return err
 	}
 
-	fmt.Println(nextVerStr)
+	// Record the consumed changesets before they are removed
+	var manifestPath string
+	if opts.manifest {
+		manifest := newReleaseManifest(nextVerStr, changes, changelogOpts)
+		if manifestPath, err = writeReleaseManifest(p, manifest); err != nil {
+			return err
+		}
+	}
+
+	if cfg.MaintainIndex {
+		if err := appendIndex(p, newIndexEntries(nextVerStr, "", changelogOpts.date, changes)); err != nil {
+			return err
+		}
+	}
+
+	// Clean up changeset files, saying so since it is easy to miss
+	removed := len(changes)
+	if !opts.keep {
+		removed, err = cleanupChanges(p.changes)
+		if err := checkCleanup(p.changes, nextVerStr, err); err != nil {
+			return err
+		}
+	}
+	if !opts.quiet {
+		printCleanupSummary(os.Stderr, removed, opts.keep)
+	}
+
+	return printReleaseResult(releaseResult{
+		Previous:      previous,
+		Version:       nextVerStr,
+		ChangelogPath: changelogFile,
+		Consumed:      len(changes),
+		Manifest:      manifestPath,
+	}, opts.json, prettyJSON(opts.pretty))
+}
+
+// renderReleaseNotes handles release --output: the section is written to
+// opts.output, but nothing is released, so the version and the pending
+// changesets are left for a release that updates the changelog. result is
+// printed unless the section itself went to stdout.
+func renderReleaseNotes(result releaseResult, section string, opts releaseOptions) error {
+	if err := writeReleaseNotes(opts.output, section); err != nil {
+		return err
+	}
+	if opts.output == "-" {
+		return nil
+	}
+	result.ChangelogPath = opts.output
+	return printReleaseResult(result, opts.json, prettyJSON(opts.pretty))
+}
+
+// printReleaseDryRun shows the release cmdRelease would make without writing
+// anything: the next version and changelog section, or with --diff unified
+// diffs of CHANGELOG.md and config.json computed in memory, making the same
+// edits as the release would.
+func printReleaseDryRun(w io.Writer, p paths, cfg *config, next, section string, changelogOpts changelogOptions, opts releaseOptions) error {
+	fmt.Fprintf(w, "Next version: %s\n\n", next)
+	if !opts.diff {
+		fmt.Fprint(w, section)
+		return nil
+	}
+
+	var oldChangelog string
+	if data, err := os.ReadFile(filepath.Join(p.root, changelogFile)); err == nil {
+		oldChangelog = string(data)
+	}
+	newChangelog, err := insertChangelogSection(oldChangelog, section, cfg.changelogHeading(), changelogFile, opts.replace)
+	if err != nil {
+		return err
+	}
+	if changelogOpts.compareLinks {
+		if link := compareLink(changelogOpts.repoURL, changelogOpts.previousVersion, next); link != "" {
+			newChangelog = insertCompareLink(newChangelog, link)
+		}
+	}
+
+	oldConfig, err := os.ReadFile(p.config)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	released := *cfg
+	released.Version = next
+	newConfig, err := marshalConfig(&released)
+	if err != nil {
+		return err
+	}
+	configName, err := filepath.Rel(p.root, p.config)
+	if err != nil {
+		configName = p.config
+	}
+
+	archiveFile := changelogArchivePath(changelogFile)
+	var oldArchive string
+	if data, err := os.ReadFile(filepath.Join(p.root, archiveFile)); err == nil {
+		oldArchive = string(data)
+	}
+	newChangelog, newArchive, err := archiveChangelogSections(newChangelog, oldArchive, archiveFile, cfg.MaxChangelogSections)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, unifiedDiff(changelogFile, oldChangelog, normalizeChangelog(newChangelog)))
+	if newArchive != oldArchive {
+		fmt.Fprint(w, unifiedDiff(archiveFile, oldArchive, normalizeChangelog(newArchive)))
+	}
+	fmt.Fprint(w, unifiedDiff(filepath.ToSlash(configName), string(oldConfig), string(newConfig)))
+	return nil
+}
+
+// checkGitTimeout reports a git timeout hit while preparing a release, which
+// leaves commit SHAs and subjects out of the changelog: an error when
+// failOnGitTimeout is set, a warning on w otherwise.
+func checkGitTimeout(w io.Writer, cfg *config) error {
+	if gitTimeoutErr == nil {
+		return nil
+	}
+	if cfg.FailOnGitTimeout {
+		return fmt.Errorf("%w; raise gitTimeout in config.json or unset failOnGitTimeout", gitTimeoutErr)
+	}
+	fmt.Fprintf(w, "warning: %s; commit information is left out of the changelog\n", gitTimeoutErr)
 	return nil
 }
 
+// noVersionChange handles a release where every pending changeset is a none
+// bump. The version would not change, so there is nothing to release: that is
+// an error unless --allow-empty is given, and the notes stay pending for the
+// next release either way.
+func noVersionChange(opts releaseOptions) error {
+	if !opts.allowEmpty {
+		return fmt.Errorf("only none changesets are pending, so the version would not change; pass --allow-empty to skip the release")
+	}
+	if !opts.quiet {
+		fmt.Fprintln(os.Stderr, "Only none changesets are pending; nothing released.")
+	}
+	return nil
+}
+
+// ensureCleanTree refuses to release from a git working tree with uncommitted
+// changes, other than the pending changesets the release consumes. Outside a
+// git repository there is nothing to check. A git timeout skips the check
+// with a warning unless failOnGitTimeout is set; any other git failure is an
+// error.
+func ensureCleanTree(p paths, cfg *config) error {
+	dirty, err := gitDirtyFiles(p.root)
+	switch {
+	case err == nil:
+	case isNotWorkTree(err):
+		return nil
+	case errors.Is(err, errGitTimeout) && !cfg.FailOnGitTimeout:
+		fmt.Fprintf(os.Stderr, "warning: %s; the working tree was not checked for uncommitted changes\n", err)
+		return nil
+	case errors.Is(err, errGitTimeout):
+		return fmt.Errorf("%w; raise gitTimeout in config.json or unset failOnGitTimeout", err)
+	default:
+		return fmt.Errorf("failed to check the working tree for uncommitted changes: %w", err)
+	}
+
+	// git reports paths with symlinks resolved.
+	root, changesDir := p.root, p.changes
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(changesDir); err == nil {
+		changesDir = resolved
+	}
+
+	var others []string
+	for _, path := range dirty {
+		if filepath.Dir(path) == changesDir && isChangesetFile(filepath.Base(path)) {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		others = append(others, filepath.ToSlash(rel))
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("working tree has uncommitted changes (%s); commit or stash them, or pass --allow-dirty", strings.Join(others, ", "))
+}
+
+// loadChangelogTemplate returns the changelog template source to use for a
+// release: the --template-file flag wins over the changelogTemplate config
+// field (relative to the project root). An empty result selects the built-in
+// template.
+func loadChangelogTemplate(root string, cfg *config, flagPath string) (string, error) {
+	path := flagPath
+	if path == "" && cfg.ChangelogTemplate != "" {
+		path = cfg.ChangelogTemplate
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read changelog template: %w", err)
+	}
+	return string(data), nil
+}
+
+// writeReleaseNotes writes a rendered changelog section to path, to stdout
+// when path is "-", or to the clipboard when path is "clipboard".
+func writeReleaseNotes(path, section string) error {
+	switch path {
+	case "-":
+		fmt.Print(section)
+		return nil
+	case outputClipboard:
+		writeClipboardNotes(os.Stderr, section)
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(section), 0644); err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
+	}
+	return nil
+}
+
+// stepSummaryEnv names the file whose markdown GitHub Actions shows on the
+// run's summary page.
+const stepSummaryEnv = "GITHUB_STEP_SUMMARY"
+
+// appendStepSummary appends a released changelog section to the GitHub
+// Actions step summary. Outside Actions, where the variable is unset, it does
+// nothing.
+func appendStepSummary(section string) error {
+	path := os.Getenv(stepSummaryEnv)
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", stepSummaryEnv, err)
+	}
+	if _, err := fmt.Fprintf(f, "%s\n", section); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", stepSummaryEnv, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", stepSummaryEnv, err)
+	}
+	return nil
+}
+
+// confirmRelease previews the next version and changelog section and asks the
+// user to confirm. It reports false, after printing "Aborted.", when declined.
+func confirmRelease(scanner *bufio.Scanner, ver, section string) (bool, error) {
+	fmt.Printf("Next version: %s\n", ver)
+	fmt.Println()
+	fmt.Println("--- Changelog ---")
+	fmt.Println()
+	fmt.Print(section)
+	fmt.Println()
+	fmt.Println("--- End Changelog ---")
+	fmt.Println()
+	fmt.Print("Proceed? (y/n): ")
+
+	if !scanner.Scan() {
+		return false, fmt.Errorf("no input received")
+	}
+	confirm := strings.TrimSpace(scanner.Text())
+	if !strings.EqualFold(confirm, "y") {
+		fmt.Println("Aborted.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// printReleaseResult prints the released version, or the full result as JSON.
+func printReleaseResult(result releaseResult, asJSON, pretty bool) error {
+	if !asJSON {
+		fmt.Println(result.Version)
+		return nil
+	}
+
+	if err := writeJSON(os.Stdout, result, pretty); err != nil {
+		return fmt.Errorf("failed to write release result: %w", err)
+	}
+	return nil
+}
+
+// cmdConfig dispatches the config subcommands.
+func cmdConfig(p paths, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing config subcommand, expected schema or effective")
+	}
+
+	switch args[0] {
+	case "schema":
+		return printConfigSchema(os.Stdout)
+	case "effective":
+		if err := ensureChangesetsExist(p); err != nil {
+			return err
+		}
+		cfg, err := loadConfig(p.config)
+		if err != nil {
+			return err
+		}
+		return printEffectiveConfig(os.Stdout, cfg)
+	default:
+		return fmt.Errorf("unknown config subcommand: %s", args[0])
+	}
+}
+
 // calculateNextVersion reads the current version and all changesets, then computes the next version.
-func calculateNextVersion(p paths) (string, []*changeset, *config, error) {
+// With lenientBump, unknown bump types are read as patch with a warning. With
+// allowPrereleaseBump, a prerelease only advances its counter whatever the bump;
+// otherwise minor and major changesets finalize it and bump (see nextVersion).
+// Calendar versions are computed for date, or for today when it is zero.
+func calculateNextVersion(p paths, lenientBump, allowPrereleaseBump bool, date time.Time) (string, []*changeset, *config, error) {
 	cfg, err := loadConfig(p.config)
 	if err != nil {
 		return "", nil, nil, err
 	}
 
-	changes, err := listChangesets(p.changes)
+	parseOpts := cfg.parseOptions()
+	parseOpts.lenientBump = lenientBump
+	changes, err := listChangesets(p.changes, parseOpts)
 	if err != nil {
 		return "", nil, nil, err
 	}
@@ -295,81 +1485,127 @@ func calculateNextVersion(p paths) (string, []*changeset, *config, error) {
 		return cfg.Version, nil, cfg, nil
 	}
 
-	// Parse current version
-	currentVersion := strings.TrimPrefix(cfg.Version, "v")
-	ver, err := semver.NewVersion(currentVersion)
+	inc, err := cfg.versionIncrementer(allowPrereleaseBump, date)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	nextVerStr, err := nextVersionFor(cfg.Version, changes, inc)
 	if err != nil {
-		return "", nil, nil, fmt.Errorf("failed to parse current version %q: %w", cfg.Version, err)
+		return "", nil, nil, err
 	}
 
-	// Determine highest bump
-	bump := highestBump(changes)
+	return nextVerStr, changes, cfg, nil
+}
 
-	// Apply bump
-	var next semver.Version
-	switch bump {
-	case major:
-		next = ver.IncMajor()
-	case minor:
-		next = ver.IncMinor()
-	case patch:
-		next = ver.IncPatch()
+// previewNextVersion computes the next version as if a changeset with the given
+// bump were added to the pending ones.
+func previewNextVersion(p paths, bump bumpType) (string, error) {
+	_, changes, cfg, err := calculateNextVersion(p, false, false, time.Time{})
+	if err != nil {
+		return "", err
 	}
 
-	nextVerStr := "v" + next.String()
-	return nextVerStr, changes, cfg, nil
+	highest := highestBump(append(changes, &changeset{bump: bump}))
+	return cfg.incrementVersion(cfg.Version, highest)
 }
 
-// buildChangelogSection produces the markdown section for a release.
-func buildChangelogSection(ver string, changes []*changeset) string {
+// buildChangelogSection produces the markdown section for a release by
+// rendering opts.template, or the built-in template when it is empty. Every
+// formatting choice comes from opts, so with opts.date and opts.commitSHA set
+// it renders changesets built in memory without touching git or the clock.
+func buildChangelogSection(ver string, changes []*changeset, opts changelogOptions) (string, error) {
+	tmpl, err := parseChangelogTemplate(opts.template)
+	if err != nil {
+		return "", err
+	}
+
 	var sb strings.Builder
+	date := opts.date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	if err := tmpl.Execute(&sb, newChangelogData(ver, date, changes, opts)); err != nil {
+		return "", fmt.Errorf("failed to render changelog template: %w", err)
+	}
 
-	date := time.Now().Format("2006-01-02")
-	sb.WriteString(fmt.Sprintf("## %s - %s\n", ver, date))
+	return sb.String(), nil
+}
+
+// changelogInsertMarker marks where new sections go in a changelog with a
+// preamble. Without it they are inserted after the first "# " heading.
+const changelogInsertMarker = "<!-- changesets:insert -->"
 
-	// Group by bump type
-	groups := map[bumpType][]*changeset{
-		major: {},
-		minor: {},
-		patch: {},
+// prependChangelog prepends a new section to CHANGELOG.md, creating it with
+// the heading line heading (or "# Changelog" when empty) if it does not exist.
+// See insertChangelogSection for where the section goes. When the changelog
+// then has more than maxSections release sections, the oldest move to the
+// archive file next to it; zero keeps them all.
+func prependChangelog(path, section, heading string, replace bool, maxSections int) error {
+	var existing string
+	if data, err := os.ReadFile(path); err == nil {
+		existing = string(data)
 	}
-	for _, cs := range changes {
-		groups[cs.bump] = append(groups[cs.bump], cs)
+
+	content, err := insertChangelogSection(existing, section, heading, filepath.Base(path), replace)
+	if err != nil {
+		return err
 	}
 
-	// Write each group in order: major, minor, patch
-	writeGroup := func(title string, items []*changeset) {
-		if len(items) == 0 {
-			return
-		}
-		sb.WriteString(fmt.Sprintf("\n### %s\n\n", title))
-		for _, cs := range items {
-			sha, _ := getFileCommitSHA(cs.filepath)
-			if sha != "" {
-				sb.WriteString(fmt.Sprintf("- %s: %s\n", sha, cs.summary))
-			} else {
-				sb.WriteString(fmt.Sprintf("- %s\n", cs.summary))
-			}
+	archivePath := changelogArchivePath(path)
+	var oldArchive string
+	if data, err := os.ReadFile(archivePath); err == nil {
+		oldArchive = string(data)
+	}
+	content, archive, err := archiveChangelogSections(content, oldArchive, filepath.Base(archivePath), maxSections)
+	if err != nil {
+		return err
+	}
+	// Write the archive first so a failure cannot lose the moved sections.
+	if archive != oldArchive {
+		if err := writeChangelog(archivePath, archive); err != nil {
+			return err
 		}
 	}
-
-	writeGroup("Major Changes", groups[major])
-	writeGroup("Minor Changes", groups[minor])
-	writeGroup("Patch Changes", groups[patch])
-
-	return sb.String()
+	return writeChangelog(path, content)
 }
 
-// prependChangelog prepends a new section to CHANGELOG.md.
-func prependChangelog(path string, section string) error {
-	var existing string
-	if data, err := os.ReadFile(path); err == nil {
-		existing = string(data)
+// insertChangelogSection returns the changelog content existing with section
+// added, starting a new changelog with heading when existing is empty. When
+// the changelog contains changelogInsertMarker, the section goes right below
+// the marker line instead of below the heading.
+// If the changelog already has a section for the same version, it is replaced
+// in place when replace is set and ErrVersionReleased, naming the file name,
+// is returned otherwise.
+func insertChangelogSection(existing, section, heading, name string, replace bool) (string, error) {
+	if existingSection, ok := findChangelogSection(existing, section); ok {
+		if !replace {
+			return "", fmt.Errorf("%w: %s has a section for %s, pass --replace to overwrite it", ErrVersionReleased, name, existingSection.version)
+		}
+
+		content := existing[:existingSection.start] + section
+		if existingSection.end < len(existing) {
+			content += "\n" + existing[existingSection.end:]
+		}
+		return content, nil
 	}
 
 	var content string
 	if existing == "" {
-		content = "# Changelog\n\n" + section
+		if heading == "" {
+			heading = "# " + defaultChangelogTitle
+		}
+		content = heading + "\n\n" + section
+	} else if marker := strings.Index(existing, changelogInsertMarker); marker >= 0 {
+		// Insert after the marker line, keeping the marker for the next release
+		end := len(existing)
+		if idx := strings.Index(existing[marker:], "\n"); idx >= 0 {
+			end = marker + idx
+		}
+		rest := strings.TrimLeft(existing[end:], "\n")
+		content = existing[:end] + "\n\n" + section
+		if rest != "" {
+			content += "\n" + rest
+		}
 	} else {
 		// Insert after the first line (# Changelog header) if it exists
 		if strings.HasPrefix(existing, "# ") {
@@ -387,40 +1623,90 @@ func prependChangelog(path string, section string) error {
 		}
 	}
 
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write CHANGELOG.md: %w", err)
-	}
-
-	return nil
+	return content, nil
 }
 
-// cleanupChanges removes all .md files from the changes directory, keeping .gitkeep.
-func cleanupChanges(dir string) error {
+// cleanupChanges removes all changeset files from the changes directory,
+// keeping .gitkeep and README.md, and returns how many were removed.
+func cleanupChanges(dir string) (int, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return fmt.Errorf("failed to read changes directory: %w", err)
+		return 0, fmt.Errorf("failed to read changes directory: %w", err)
 	}
 
+	removed := 0
+
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		if !strings.HasSuffix(entry.Name(), ".md") {
+		if !isChangesetFile(entry.Name()) {
 			continue
 		}
 		path := filepath.Join(dir, entry.Name())
-		if err := os.Remove(path); err != nil {
-			return fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		if err := removeFile(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
 		}
+		removed++
 	}
 
-	return nil
+	return removed, nil
+}
+
+// removeFile deletes a consumed changeset. Tests override it to simulate a
+// file that cannot be removed.
+var removeFile = os.Remove
+
+// checkCleanup makes sure no changeset survived the cleanup after releasing
+// ver. By then the changelog and config already include the changesets, so a
+// leftover one would be released a second time; the error names every such
+// file. cleanupErr is the error cleanupChanges returned, if any.
+func checkCleanup(dir, ver string, cleanupErr error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read changes directory: %w", err)
+	}
+
+	var left []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isChangesetFile(entry.Name()) {
+			left = append(left, entry.Name())
+		}
+	}
+	return leftoverError(ver, left, cleanupErr)
+}
+
+// leftoverError names the changeset files left pending after releasing ver,
+// wrapping cleanupErr, or returns cleanupErr when none are left.
+func leftoverError(ver string, left []string, cleanupErr error) error {
+	if len(left) == 0 {
+		return cleanupErr
+	}
+	const format = "released %s, but these changesets are still pending: %s; delete them by hand or the next release will include them again"
+	if cleanupErr != nil {
+		return fmt.Errorf(format+" (%w)", ver, strings.Join(left, ", "), cleanupErr)
+	}
+	return fmt.Errorf(format, ver, strings.Join(left, ", "))
+}
+
+// printCleanupSummary tells the user what release did with the consumed
+// changeset files, pointing at --keep when they were removed.
+func printCleanupSummary(w io.Writer, n int, kept bool) {
+	files := "changeset files"
+	if n == 1 {
+		files = "changeset file"
+	}
+	if kept {
+		fmt.Fprintf(w, "Kept %d %s (--keep)\n", n, files)
+		return
+	}
+	fmt.Fprintf(w, "Removed %d %s (use --keep to retain)\n", n, files)
 }
 
 // ensureChangesetsExist checks that the .changesets directory exists.
 func ensureChangesetsExist(p paths) error {
 	if _, err := os.Stat(p.changesets); os.IsNotExist(err) {
-		return fmt.Errorf(".changesets directory not found. Run 'changesets init' first")
+		return fmt.Errorf("%w. Run 'changesets init' first", ErrNotInitialized)
 	}
 	return nil
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,8 +12,17 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the releases made by tests off the CI run's summary page, and
+	// let them compute their own versions.
+	os.Unsetenv(stepSummaryEnv)
+	os.Unsetenv(releaseVersionEnv)
+	os.Exit(m.Run())
+}
+
 // setupProject creates a temporary project directory with .changesets structure.
 func setupProject(t *testing.T, version string, changesetContents ...string) paths {
 	t.Helper()
@@ -84,6 +95,9 @@ func TestRunHelp(t *testing.T) {
 	if !strings.Contains(output, "changesets") {
 		t.Error("help output missing")
 	}
+	if !strings.Contains(output, "(aliases: new, create)") || !strings.Contains(output, "(alias: bump)") {
+		t.Error("help output should note command aliases")
+	}
 }
 
 func TestRunHelpLong(t *testing.T) {
@@ -198,6 +212,79 @@ func TestRunAdd(t *testing.T) {
 	}
 }
 
+func TestRunAutoInit(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	var code int
+	captureStdout(func() {
+		code = run([]string{"changesets", "add", "--bump", "patch", "-m", "Fix bug"}, strings.NewReader(""))
+	})
+	if code != 1 {
+		t.Errorf("expected add to fail before init, got exit code %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, changesetsDir)); !os.IsNotExist(err) {
+		t.Fatal("expected no .changesets without --auto-init")
+	}
+
+	output := captureStdout(func() {
+		code = run([]string{"changesets", "--auto-init", "add", "--bump", "patch", "-m", "Fix bug"}, strings.NewReader(""))
+	})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if strings.Contains(output, "Initialized") {
+		t.Errorf("expected auto-init to be silent, got:\n%s", output)
+	}
+	cfg, err := loadConfig(filepath.Join(dir, changesetsDir, configFile))
+	if err != nil || cfg.Version != "v0.0.0" {
+		t.Fatalf("expected the init config, got %+v (%v)", cfg, err)
+	}
+
+	// An existing directory is left as it is.
+	captureStdout(func() {
+		code = run([]string{"changesets", "--auto-init", "add", "--bump", "minor", "-m", "New feature"}, strings.NewReader(""))
+	})
+	changes, _ := listChangesets(filepath.Join(dir, changesetsDir, changesDir), parseOptions{})
+	if code != 0 || len(changes) != 2 {
+		t.Errorf("expected both changesets to be kept, got exit code %d and %d changesets", code, len(changes))
+	}
+}
+
+func TestRunPromptsReadInjectedInput(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	captureStdout(func() { run([]string{"changesets", "init"}, strings.NewReader("")) })
+
+	// The recreate prompt must read the reader passed to run, not os.Stdin.
+	output := captureStdout(func() {
+		run([]string{"changesets", "init"}, strings.NewReader("n\n"))
+	})
+	if !strings.Contains(output, "Aborted.") {
+		t.Errorf("expected init to read the injected answer, got:\n%s", output)
+	}
+
+	// Every add prompt reads from the same reader, in order.
+	var code int
+	captureStdout(func() {
+		code = run([]string{"changesets", "add"}, strings.NewReader("2\nInjected summary\ny\n"))
+	})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	changes, _ := listChangesets(filepath.Join(dir, changesetsDir, changesDir), parseOptions{})
+	if len(changes) != 1 || changes[0].bump != minor || changes[0].summary != "Injected summary" {
+		t.Errorf("expected a minor changeset from the injected input, got %v", changes)
+	}
+}
+
 func TestRunNext(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
@@ -235,6 +322,37 @@ func TestRunRelease(t *testing.T) {
 	}
 }
 
+func TestRunAliases(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	captureStdout(func() { run([]string{"changesets", "init"}, strings.NewReader("")) })
+
+	for _, alias := range []string{"new", "create"} {
+		var code int
+		captureStdout(func() {
+			code = run([]string{"changesets", alias, "--bump", "minor", "-m", "Added " + alias}, strings.NewReader(""))
+		})
+		if code != 0 {
+			t.Errorf("%s: expected exit code 0, got %d", alias, code)
+		}
+	}
+
+	var code int
+	output := captureStdout(func() {
+		code = run([]string{"changesets", "bump"}, strings.NewReader(""))
+	})
+	if code != 0 {
+		t.Fatalf("bump: expected exit code 0, got %d", code)
+	}
+	if strings.TrimSpace(output) != "v0.1.0" {
+		t.Errorf("expected bump to release both changesets as v0.1.0, got %q", output)
+	}
+}
+
 func TestRunCommandError(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
@@ -304,7 +422,7 @@ func TestCmdInitFresh(t *testing.T) {
 
 	var err error
 	output := captureStdout(func() {
-		err = cmdInit(p, newScanner(""))
+		err = cmdInit(p, newScanner(""), initOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdInit failed: %v", err)
@@ -319,12 +437,179 @@ func TestCmdInitFresh(t *testing.T) {
 	}
 }
 
+func TestCmdInitReadmeCommands(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	p := newPaths(dir)
+
+	var err error
+	captureStdout(func() {
+		err = cmdInit(p, newScanner(""), initOptions{binary: "changesets"})
+	})
+	if err != nil {
+		t.Fatalf("cmdInit failed: %v", err)
+	}
+
+	data, readErr := os.ReadFile(p.readme)
+	if readErr != nil {
+		t.Fatalf("README.md not written: %v", readErr)
+	}
+	readme := string(data)
+	if !strings.Contains(readme, "`changesets add`") {
+		t.Errorf("expected README to mention `changesets add`, got:\n%s", readme)
+	}
+	if !strings.Contains(readme, "`changesets release`") {
+		t.Errorf("expected README to mention `changesets release`, got:\n%s", readme)
+	}
+	if strings.Contains(readme, "go-changesets") {
+		t.Errorf("README should not reference go-changesets, got:\n%s", readme)
+	}
+}
+
+func TestCmdInitReadmeCustomBinary(t *testing.T) {
+	dir := t.TempDir()
+	p := newPaths(dir)
+
+	var err error
+	captureStdout(func() {
+		err = cmdInit(p, newScanner(""), initOptions{binary: "cs"})
+	})
+	if err != nil {
+		t.Fatalf("cmdInit failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(p.readme)
+	if !strings.Contains(string(data), "`cs add`") {
+		t.Errorf("expected README to use the binary name, got:\n%s", data)
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	tests := []struct {
+		arg0     string
+		expected string
+	}{
+		{"changesets", "changesets"},
+		{"/usr/local/bin/changesets", "changesets"},
+		{"/tmp/go-build123/b001/exe/changesets", "changesets"},
+		{"changesets.exe", "changesets"},
+		{"cs", "cs"},
+		{"", "changesets"},
+	}
+
+	for _, tt := range tests {
+		if got := binaryName(tt.arg0); got != tt.expected {
+			t.Errorf("binaryName(%q) = %q, expected %q", tt.arg0, got, tt.expected)
+		}
+	}
+}
+
+func TestCmdInitNoReadmeNoGitkeep(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	p := newPaths(dir)
+
+	var err error
+	captureStdout(func() {
+		err = cmdInit(p, newScanner(""), initOptions{noReadme: true, noGitkeep: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdInit failed: %v", err)
+	}
+	for _, path := range []string{p.changes, p.config} {
+		if _, statErr := os.Stat(path); statErr != nil {
+			t.Errorf("expected %s to exist", path)
+		}
+	}
+	for _, path := range []string{p.readme, p.gitkeep} {
+		if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+			t.Errorf("expected %s to be skipped", path)
+		}
+	}
+}
+
+func TestCmdInitFlat(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	p := newPaths(dir)
+
+	var err error
+	captureStdout(func() {
+		err = cmdInit(p, newScanner(""), initOptions{flat: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdInit failed: %v", err)
+	}
+	if _, statErr := os.Stat(p.changes); !os.IsNotExist(statErr) {
+		t.Error("expected no changes subdirectory in the flat layout")
+	}
+	cfg, err := loadConfig(p.config)
+	if err != nil || !cfg.FlatLayout {
+		t.Fatalf("expected flatLayout in config, got %+v (%v)", cfg, err)
+	}
+
+	// Changesets sit next to config.json and README.md, which are not read.
+	p = p.flat()
+	os.WriteFile(filepath.Join(p.changes, "fix.md"), []byte("---\ntest: patch\n---\n\nFixed bug"), 0644)
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(p.changes, "fix.md")); !os.IsNotExist(statErr) {
+		t.Error("expected the changeset to be removed")
+	}
+	for _, path := range []string{p.config, p.readme} {
+		if _, statErr := os.Stat(path); statErr != nil {
+			t.Errorf("expected %s to be kept", path)
+		}
+	}
+}
+
+func TestCmdInitDryRun(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	p := newPaths(dir)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdInit(p, newScanner(""), initOptions{dryRun: true, noGitkeep: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdInit failed: %v", err)
+	}
+	if !strings.Contains(output, "Project root: "+dir) || !strings.Contains(output, "Initial version: v0.0.0") {
+		t.Errorf("expected root and version in the plan, got:\n%s", output)
+	}
+	if !strings.Contains(output, "  "+p.config+"\n") || strings.Contains(output, p.gitkeep) {
+		t.Errorf("expected config but not .gitkeep in the plan, got:\n%s", output)
+	}
+	if _, statErr := os.Stat(p.changesets); !os.IsNotExist(statErr) {
+		t.Error("expected nothing to be written in a dry run")
+	}
+}
+
+func TestParseInitFlags(t *testing.T) {
+	opts, err := parseInitFlags([]string{"--no-readme", "--no-gitkeep"})
+	if err != nil {
+		t.Fatalf("parseInitFlags failed: %v", err)
+	}
+	if !opts.noReadme || !opts.noGitkeep {
+		t.Errorf("expected both flags set, got %+v", opts)
+	}
+
+	if _, err := parseInitFlags([]string{"--bogus"}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
+}
+
 func TestCmdInitExistingYes(t *testing.T) {
 	p := setupProject(t, "v0.0.0")
 
 	var err error
 	output := captureStdout(func() {
-		err = cmdInit(p, newScanner("y\n"))
+		err = cmdInit(p, newScanner("y\n"), initOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdInit failed: %v", err)
@@ -339,7 +624,7 @@ func TestCmdInitExistingNo(t *testing.T) {
 
 	var err error
 	output := captureStdout(func() {
-		err = cmdInit(p, newScanner("n\n"))
+		err = cmdInit(p, newScanner("n\n"), initOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdInit failed: %v", err)
@@ -354,7 +639,7 @@ func TestCmdInitExistingNoInput(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdInit(p, newScanner(""))
+		err = cmdInit(p, newScanner(""), initOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error for no input")
@@ -366,22 +651,74 @@ func TestCmdAddPatch(t *testing.T) {
 
 	var err error
 	output := captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\nFixed a bug\ny\n"))
+		err = cmdAdd(p, newScanner("1\nFixed a bug\ny\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd failed: %v", err)
 	}
-	if !strings.Contains(output, "Created changeset") {
+	if !strings.Contains(output, "Created changeset: .changesets/changes/") {
 		t.Error("expected 'Created changeset' message")
 	}
 }
 
+func TestCmdAddNextSteps(t *testing.T) {
+	p := setupProject(t, "v1.2.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "minor", messages: []string{"Added feature"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if !strings.Contains(output, "Releasing now would produce v1.3.0.") {
+		t.Errorf("expected next version hint, got %q", output)
+	}
+	if !strings.Contains(output, "Remember to commit .changesets/changes/") {
+		t.Errorf("expected commit reminder, got %q", output)
+	}
+
+	output = captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fixed typo"}, quiet: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if strings.Contains(output, "Releasing now") || strings.Contains(output, "Remember to commit") {
+		t.Errorf("expected no hint with quiet, got %q", output)
+	}
+}
+
+func TestCmdAddFullModulePath(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	os.WriteFile(filepath.Join(p.root, "go.mod"), []byte("module github.com/me/tools\n"), 0644)
+	if err := saveConfig(p.config, &config{Version: "v1.0.0", UseFullModulePath: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fixed bug"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+
+	changes, err := listChangesets(p.changes, parseOptions{})
+	if err != nil {
+		t.Fatalf("listChangesets failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].repoName != "github.com/me/tools" {
+		t.Fatalf("expected a changeset for github.com/me/tools, got %v", changes)
+	}
+}
+
 func TestCmdAddMinor(t *testing.T) {
 	p := setupProject(t, "v0.0.0")
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("2\nNew feature\ny\n"))
+		err = cmdAdd(p, newScanner("2\nNew feature\ny\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd failed: %v", err)
@@ -393,7 +730,7 @@ func TestCmdAddMajor(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("3\nBreaking change\ny\n"))
+		err = cmdAdd(p, newScanner("3\nBreaking change\ny\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd failed: %v", err)
@@ -405,7 +742,7 @@ func TestCmdAddPatchText(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("patch\nFix\ny\n"))
+		err = cmdAdd(p, newScanner("patch\nFix\ny\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd failed: %v", err)
@@ -417,7 +754,7 @@ func TestCmdAddMinorText(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("minor\nFeat\ny\n"))
+		err = cmdAdd(p, newScanner("minor\nFeat\ny\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd failed: %v", err)
@@ -429,7 +766,7 @@ func TestCmdAddMajorText(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("major\nBreaking\ny\n"))
+		err = cmdAdd(p, newScanner("major\nBreaking\ny\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd failed: %v", err)
@@ -441,7 +778,7 @@ func TestCmdAddInvalidSelection(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("invalid\n"))
+		err = cmdAdd(p, newScanner("invalid\n"), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error for invalid selection")
@@ -453,7 +790,7 @@ func TestCmdAddEmptySummary(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\n\n"))
+		err = cmdAdd(p, newScanner("1\n\n"), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error for empty summary")
@@ -465,7 +802,7 @@ func TestCmdAddAbort(t *testing.T) {
 
 	var err error
 	output := captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\nSome change\nn\n"))
+		err = cmdAdd(p, newScanner("1\nSome change\nn\n"), addOptions{})
 	})
 	if err != nil {
 		t.Fatalf("cmdAdd should not error on abort: %v", err)
@@ -480,7 +817,7 @@ func TestCmdAddNoInputBump(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner(""))
+		err = cmdAdd(p, newScanner(""), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error for no input on bump")
@@ -492,7 +829,7 @@ func TestCmdAddNoInputSummary(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\n"))
+		err = cmdAdd(p, newScanner("1\n"), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error for no input on summary")
@@ -504,7 +841,7 @@ func TestCmdAddNoInputConfirm(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\nSome change\n"))
+		err = cmdAdd(p, newScanner("1\nSome change\n"), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error for no input on confirmation")
@@ -514,7 +851,7 @@ func TestCmdAddNoInputConfirm(t *testing.T) {
 func TestCmdAddNoChangesetsDir(t *testing.T) {
 	p := newPaths(t.TempDir())
 
-	err := cmdAdd(p, newScanner("1\ntest\ny\n"))
+	err := cmdAdd(p, newScanner("1\ntest\ny\n"), addOptions{})
 	if err == nil {
 		t.Fatal("expected error when .changesets doesn't exist")
 	}
@@ -530,7 +867,7 @@ func TestCmdAddModuleNameError(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\ntest\ny\n"))
+		err = cmdAdd(p, newScanner("1\ntest\ny\n"), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error when moduleName fails")
@@ -547,97 +884,1288 @@ func TestCmdAddWriteError(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdAdd(p, newScanner("1\ntest change\ny\n"))
+		err = cmdAdd(p, newScanner("1\ntest change\ny\n"), addOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error when changes dir is read-only")
 	}
 }
 
-func TestCmdNext(t *testing.T) {
-	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded feature")
+func TestCmdAddAuthor(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
 
 	var err error
-	output := captureStdout(func() {
-		err = cmdNext(p)
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fix"}, author: "@alice"})
 	})
 	if err != nil {
-		t.Fatalf("cmdNext failed: %v", err)
+		t.Fatalf("cmdAdd failed: %v", err)
 	}
-	if strings.TrimSpace(output) != "v1.1.0" {
-		t.Errorf("expected v1.1.0, got %q", strings.TrimSpace(output))
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].author != "@alice" {
+		t.Fatalf("expected changeset credited to @alice, got %+v", changes)
 	}
 }
 
-func TestCmdNextNoChangesets(t *testing.T) {
-	p := setupProject(t, "v1.0.0")
-
-	var err error
-	output := captureStdout(func() {
-		err = cmdNext(p)
-	})
+func TestCmdAddLongSummaryLine(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	summary := strings.Repeat("a", 200*1024)
+
+	// The preview echoes the summary, which would fill a captureStdout pipe.
+	devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer devNull.Close()
+	oldStdout := os.Stdout
+	os.Stdout = devNull
+	err := cmdAdd(p, newInputScanner(strings.NewReader("1\n"+summary+"\ny\n")), addOptions{})
+	os.Stdout = oldStdout
 	if err != nil {
-		t.Fatalf("cmdNext failed: %v", err)
-	}
-	if strings.TrimSpace(output) != "v1.0.0" {
-		t.Errorf("expected v1.0.0, got %q", strings.TrimSpace(output))
+		t.Fatalf("cmdAdd failed: %v", err)
 	}
-}
 
-func TestCmdNextNoDir(t *testing.T) {
-	p := newPaths(t.TempDir())
-	if err := cmdNext(p); err == nil {
-		t.Fatal("expected error when .changesets doesn't exist")
+	changes, _ := listChangesets(p.changes, parseOptions{maxSize: defaultMaxChangesetSize})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 changeset, got %d", len(changes))
+	}
+	if changes[0].summary != summary {
+		t.Errorf("expected the full %d byte summary, got %d bytes", len(summary), len(changes[0].summary))
 	}
 }
 
-func TestCmdNextCalculateError(t *testing.T) {
-	dir := t.TempDir()
-	p := newPaths(dir)
-	os.MkdirAll(p.changesets, 0755)
-	os.MkdirAll(p.changes, 0755)
+func TestCmdAddNonInteractive(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
 
 	var err error
-	captureStdout(func() {
-		err = cmdNext(p)
+	output := captureStdout(func() {
+		// An empty scanner proves no prompt reads input.
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "minor", messages: []string{"Added feature"}})
 	})
-	if err == nil {
-		t.Fatal("expected error when config is missing")
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if strings.Contains(output, "Confirm?") {
+		t.Error("expected no confirmation prompt")
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 changeset, got %d", len(changes))
+	}
+	if changes[0].bump != minor || changes[0].summary != "Added feature" {
+		t.Errorf("unexpected changeset: bump=%s summary=%q", changes[0].bump, changes[0].summary)
 	}
 }
 
-func TestCmdRelease(t *testing.T) {
-	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+func TestCmdAddSummaryFromStdin(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
 
 	var err error
 	output := captureStdout(func() {
-		err = cmdRelease(p)
+		err = cmdAdd(p, newScanner("Added feature\n\nIt has a longer description.\n"), addOptions{bump: "minor", summaryStdin: true})
 	})
 	if err != nil {
-		t.Fatalf("cmdRelease failed: %v", err)
+		t.Fatalf("cmdAdd failed: %v", err)
 	}
-	if strings.TrimSpace(output) != "v1.0.1" {
-		t.Errorf("expected v1.0.1, got %q", strings.TrimSpace(output))
+	if strings.Contains(output, "Summary:") || strings.Contains(output, "Confirm?") {
+		t.Errorf("expected no prompts, got:\n%s", output)
 	}
 
-	data, readErr := os.ReadFile(filepath.Join(p.root, "CHANGELOG.md"))
-	if readErr != nil {
-		t.Fatal("CHANGELOG.md not created")
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 changeset, got %d", len(changes))
+	}
+	if want := "Added feature\n\nIt has a longer description."; changes[0].summary != want {
+		t.Errorf("expected summary %q, got %q", want, changes[0].summary)
+	}
+
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "minor", summaryStdin: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "summary cannot be empty") {
+		t.Errorf("expected empty stdin to be rejected, got %v", err)
+	}
+}
+
+func TestCmdAddSummaryFromStdinNoConfirm(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	err := cmdAdd(p, newScanner("Fixed a bug\n"), addOptions{noConfirm: true, summaryStdin: true})
+	if err == nil || !strings.Contains(err.Error(), "needs a .bump file") {
+		t.Fatalf("expected --no-confirm without a .bump file to be rejected, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(p.root, bumpFile), []byte("minor\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner("Fixed a bug\n"), addOptions{noConfirm: true, summaryStdin: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if changes, _ := listChangesets(p.changes, parseOptions{}); len(changes) != 1 || changes[0].bump != minor {
+		t.Errorf("expected a minor changeset from the .bump file, got %v", changes)
+	}
+}
+
+func TestCmdAddMultipleMessages(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fixed a bug", "It crashed on empty input."}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 changeset, got %d", len(changes))
+	}
+	if changes[0].summary != "Fixed a bug\n\nIt crashed on empty input." {
+		t.Errorf("expected paragraphs joined by a blank line, got %q", changes[0].summary)
+	}
+}
+
+func TestCmdAddMessageOnlyPromptsForBump(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdAdd(p, newScanner("3\ny\n"), addOptions{messages: []string{"Breaking"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if strings.Contains(output, "Summary:") {
+		t.Error("expected no summary prompt when -m is given")
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].bump != major {
+		t.Fatalf("expected 1 major changeset, got %d", len(changes))
+	}
+}
+
+func TestCmdAddBumpFileDefault(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	if err := os.WriteFile(filepath.Join(p.root, bumpFile), []byte("minor\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	output := captureStdout(func() {
+		// An empty answer accepts the bump from the file.
+		err = cmdAdd(p, newScanner("\ny\n"), addOptions{messages: []string{"Feature"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if !strings.Contains(output, "(default minor)") {
+		t.Errorf("expected prompt to show the default, got:\n%s", output)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].bump != minor {
+		t.Fatalf("expected 1 minor changeset, got %v", changes)
+	}
+}
+
+func TestCmdAddBumpFileNoConfirm(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	if err := os.WriteFile(filepath.Join(p.root, bumpFile), []byte("major"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{messages: []string{"Breaking"}, noConfirm: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].bump != major {
+		t.Fatalf("expected 1 major changeset, got %v", changes)
+	}
+}
+
+func TestCmdAddInvalidBumpFile(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	if err := os.WriteFile(filepath.Join(p.root, bumpFile), []byte("huge"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cmdAdd(p, newScanner("1\n"), addOptions{messages: []string{"x"}})
+	if err == nil || !strings.Contains(err.Error(), bumpFile) {
+		t.Fatalf("expected error naming %s, got %v", bumpFile, err)
+	}
+
+	// The file is not read when the bump is given.
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"x"}})
+	})
+	if err != nil {
+		t.Errorf("expected --bump to ignore the invalid %s, got %v", bumpFile, err)
+	}
+}
+
+func TestCmdAddDuplicate(t *testing.T) {
+	p := setupProject(t, "v0.0.0", "---\ntest: \"minor\"\n---\n\n  Added feature  \n")
+
+	err := cmdAdd(p, newScanner(""), addOptions{bump: "minor", messages: []string{"Added feature"}})
+	if err == nil || !strings.Contains(err.Error(), "change-0.md already has the same content") {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "minor", messages: []string{"Added feature"}, allowDuplicate: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd with allowDuplicate failed: %v", err)
+	}
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 2 {
+		t.Errorf("expected 2 changesets, got %d", len(changes))
+	}
+}
+
+func TestCheckPendingLimit(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"no frontmatter",
+	)
+
+	tests := []struct {
+		cfgLimit   int
+		flagLimit  int
+		wantErr    bool
+		wantWarned bool
+	}{
+		{0, 0, false, false},
+		{3, 0, false, false},
+		{2, 0, false, true},
+		{0, 2, true, false},
+		{5, 2, true, false},
+		{2, 3, false, false},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		err := checkPendingLimit(&buf, p, &config{MaxPending: tt.cfgLimit}, tt.flagLimit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkPendingLimit(config %d, flag %d) error = %v, wantErr %v", tt.cfgLimit, tt.flagLimit, err, tt.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), "2 changesets are already pending (limit 2); consider running `changesets release` first") {
+			t.Errorf("unexpected error %v", err)
+		}
+		if warned := strings.HasPrefix(buf.String(), "warning: 2 changesets are already pending"); warned != tt.wantWarned {
+			t.Errorf("checkPendingLimit(config %d, flag %d) warning = %q", tt.cfgLimit, tt.flagLimit, buf.String())
+		}
+	}
+
+	err := cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Another fix"}, maxPending: 2})
+	if err == nil {
+		t.Fatal("expected cmdAdd to refuse with --max-pending 2")
+	}
+}
+
+func TestCmdAddBumpFromBody(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdAdd(p, newScanner("Added JSON output\ny\n"), addOptions{bumpFromBody: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if !strings.Contains(output, "Inferred a minor bump") || !strings.Contains(output, "Confirm?") {
+		t.Errorf("expected the inferred bump to be confirmed, got:\n%s", output)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].bump != minor || changes[0].summary != "Added JSON output" {
+		t.Fatalf("expected 1 minor changeset, got %v", changes)
+	}
+}
+
+func TestCmdAddBumpFromBodyDeclined(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdAdd(p, newScanner("n\n"), addOptions{bumpFromBody: true, messages: []string{"BREAKING: new config"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if !strings.Contains(output, "Inferred a major bump") || !strings.Contains(output, "Aborted.") {
+		t.Errorf("expected the inferred bump to be declinable, got:\n%s", output)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 0 {
+		t.Errorf("expected no changeset after declining, got %d", len(changes))
+	}
+}
+
+func TestCmdAddFromLabels(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	labels := filepath.Join(p.root, "labels.txt")
+	os.WriteFile(labels, []byte("documentation\nsemver:minor\n"), 0644)
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{fromLabels: labels, messages: []string{"Add JSON output (#42)"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].bump != minor || changes[0].summary != "Add JSON output (#42)" {
+		t.Fatalf("expected 1 minor changeset from the labels, got %v", changes)
+	}
+
+	// A configured mapping replaces the built-in labels.
+	saveConfig(p.config, &config{Version: "v0.0.0", BumpLabels: map[string]string{"Feature": "minor"}})
+	err = cmdAdd(p, newScanner(""), addOptions{fromLabels: labels, messages: []string{"Another"}})
+	if err == nil || !strings.Contains(err.Error(), "no label in") || !strings.Contains(err.Error(), "feature") {
+		t.Errorf("expected an error listing the known labels, got %v", err)
+	}
+}
+
+func TestCmdAddSummaryMustMatch(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	saveConfig(p.config, &config{Version: "v0.0.0", SummaryMustMatch: `#\d+`})
+
+	err := cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fixed a crash"}})
+	if err == nil || !strings.Contains(err.Error(), "required pattern") {
+		t.Fatalf("expected summary pattern error, got %v", err)
+	}
+
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fixed a crash (#7)"}})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+}
+
+func TestCmdAddInvalidBumpFlag(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	err := cmdAdd(p, newScanner(""), addOptions{bump: "huge", messages: []string{"x"}})
+	if err == nil {
+		t.Fatal("expected error for invalid --bump")
+	}
+}
+
+func TestCmdAddEmptyMessage(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	err := cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"  "}})
+	if err == nil {
+		t.Fatal("expected error for empty -m")
+	}
+}
+
+func TestCmdAddPreviewVersion(t *testing.T) {
+	p := setupProject(t, "v1.2.3", "---\ntest: patch\n---\n\nFix")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdAdd(p, newScanner(""), addOptions{bump: "minor", messages: []string{"Feature"}, preview: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+	if !strings.Contains(output, "This will contribute a minor; with current pending changesets the next version would be v1.3.0.") {
+		t.Errorf("expected version preview, got:\n%s", output)
+	}
+}
+
+func TestCmdAddPreviewVersionLowerBump(t *testing.T) {
+	p := setupProject(t, "v1.2.3", "---\ntest: major\n---\n\nBreaking")
+
+	output := captureStdout(func() {
+		cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fix"}, preview: true})
+	})
+	if !strings.Contains(output, "next version would be v2.0.0.") {
+		t.Errorf("expected pending major to win, got:\n%s", output)
+	}
+}
+
+func TestParseAddFlags(t *testing.T) {
+	opts, err := parseAddFlags([]string{"-m", "First", "--message", "Second", "--bump", "patch"})
+	if err != nil {
+		t.Fatalf("parseAddFlags failed: %v", err)
+	}
+	if opts.bump != "patch" {
+		t.Errorf("expected bump patch, got %q", opts.bump)
+	}
+	if len(opts.messages) != 2 || opts.messages[0] != "First" || opts.messages[1] != "Second" {
+		t.Errorf("unexpected messages: %v", opts.messages)
+	}
+	if opts.preview {
+		t.Error("expected preview-version to default to false")
+	}
+
+	if _, err := parseAddFlags([]string{"--bogus"}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
+}
+
+func TestParseAddFlagsTypeSummary(t *testing.T) {
+	opts, err := parseAddFlags([]string{"--type", "minor", "--summary", "Added X"})
+	if err != nil {
+		t.Fatalf("parseAddFlags failed: %v", err)
+	}
+	if opts.bump != "minor" || len(opts.messages) != 1 || opts.messages[0] != "Added X" || opts.summaryStdin {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if opts, err = parseAddFlags([]string{"--type", "patch", "--summary", "-"}); err != nil || !opts.summaryStdin || len(opts.messages) != 0 {
+		t.Errorf("expected --summary - to read stdin, got %+v, %v", opts, err)
+	}
+
+	for _, args := range [][]string{
+		{"--summary", "Added X", "-m", "More"},
+		{"--summary", "-"},
+		{"--summary", "-", "--bump-from-body", "--no-confirm"},
+	} {
+		if _, err := parseAddFlags(args); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+}
+
+func TestCmdAddCustomSeparator(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	saveConfig(p.config, &config{Version: "v0.0.0", SlugSeparator: "_"})
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner("1\nFix\ny\n"), addOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdAdd failed: %v", err)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 changeset, got %d", len(changes))
+	}
+	if parts := strings.Split(changes[0].slug, "_"); len(parts) != 3 {
+		t.Errorf("expected underscore-separated slug, got %q", changes[0].slug)
+	}
+}
+
+func TestCmdAddInvalidSeparator(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	saveConfig(p.config, &config{Version: "v0.0.0", SlugSeparator: "/"})
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner("1\nFix\ny\n"), addOptions{})
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid slug separator")
+	}
+}
+
+func TestCmdAddConfigMissing(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	p := newPaths(dir)
+	os.MkdirAll(p.changes, 0755)
+
+	var err error
+	captureStdout(func() {
+		err = cmdAdd(p, newScanner("1\nFix\ny\n"), addOptions{})
+	})
+	if err == nil {
+		t.Fatal("expected error when config is missing")
+	}
+}
+
+func TestCmdNext(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded feature")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdNext(p, nextOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdNext failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v1.1.0" {
+		t.Errorf("expected v1.1.0, got %q", strings.TrimSpace(output))
+	}
+}
+
+func TestCmdNextNoChangesets(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdNext(p, nextOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdNext failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v1.0.0" {
+		t.Errorf("expected v1.0.0, got %q", strings.TrimSpace(output))
+	}
+}
+
+func TestCmdNextCustomDir(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+
+	staged := t.TempDir()
+	os.WriteFile(filepath.Join(staged, "staged.md"), []byte("---\ntest: major\n---\n\nBreaking"), 0644)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdNext(p, nextOptions{dir: staged})
+	})
+	if err != nil {
+		t.Fatalf("cmdNext failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v2.0.0" {
+		t.Errorf("expected v2.0.0 from staged changesets, got %q", strings.TrimSpace(output))
+	}
+}
+
+func TestCmdNextCustomDirMissing(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	err := cmdNext(p, nextOptions{dir: filepath.Join(t.TempDir(), "missing")})
+	if err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
+
+func TestCmdNextCustomDirNotDirectory(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	file := filepath.Join(t.TempDir(), "file.md")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	if err := cmdNext(p, nextOptions{dir: file}); err == nil {
+		t.Fatal("expected error when --dir is a file")
+	}
+}
+
+func TestCmdNextLenientBump(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: feature\n---\n\nImported")
+
+	if err := cmdNext(p, nextOptions{}); err == nil {
+		t.Fatal("expected error for unknown bump type without --lenient-bump")
+	}
+
+	output := captureStdout(func() {
+		if err := cmdNext(p, nextOptions{lenientBump: true}); err != nil {
+			t.Fatalf("cmdNext failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "v1.0.1" {
+		t.Errorf("expected unknown bump treated as patch, got %q", output)
+	}
+}
+
+func TestParseNextFlags(t *testing.T) {
+	opts, err := parseNextFlags([]string{"--dir", "staged"})
+	if err != nil {
+		t.Fatalf("parseNextFlags failed: %v", err)
+	}
+	if opts.dir != "staged" {
+		t.Errorf("expected dir staged, got %q", opts.dir)
+	}
+
+	if _, err := parseNextFlags([]string{"--bogus"}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
+}
+
+func TestCmdNextNoDir(t *testing.T) {
+	p := newPaths(t.TempDir())
+	if err := cmdNext(p, nextOptions{}); err == nil {
+		t.Fatal("expected error when .changesets doesn't exist")
+	}
+}
+
+func TestCmdNextCalculateError(t *testing.T) {
+	dir := t.TempDir()
+	p := newPaths(dir)
+	os.MkdirAll(p.changesets, 0755)
+	os.MkdirAll(p.changes, 0755)
+
+	var err error
+	captureStdout(func() {
+		err = cmdNext(p, nextOptions{})
+	})
+	if err == nil {
+		t.Fatal("expected error when config is missing")
+	}
+}
+
+func TestCmdRelease(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v1.0.1" {
+		t.Errorf("expected v1.0.1, got %q", strings.TrimSpace(output))
+	}
+
+	data, readErr := os.ReadFile(filepath.Join(p.root, "CHANGELOG.md"))
+	if readErr != nil {
+		t.Fatal("CHANGELOG.md not created")
+	}
+	if !strings.Contains(string(data), "v1.0.1") {
+		t.Error("CHANGELOG.md missing version")
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.1" {
+		t.Errorf("expected config v1.0.1, got %s", cfg.Version)
+	}
+
+	entries, _ := os.ReadDir(p.changes)
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".md") {
+			t.Errorf("changeset %s should be removed", e.Name())
+		}
+	}
+}
+
+func TestCmdReleaseKeep(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	for _, flag := range []string{"--keep", "--no-clean"} {
+		opts, err := parseReleaseFlags([]string{flag})
+		if err != nil {
+			t.Fatalf("parseReleaseFlags(%s) failed: %v", flag, err)
+		}
+		if !opts.keep {
+			t.Errorf("expected %s to set keep", flag)
+		}
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{keep: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+
+	entries, _ := os.ReadDir(p.changes)
+	found := 0
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".md") {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Errorf("expected the changeset to be kept, found %d .md files", found)
+	}
+}
+
+func TestPrintCleanupSummary(t *testing.T) {
+	tests := []struct {
+		n    int
+		kept bool
+		want string
+	}{
+		{3, false, "Removed 3 changeset files (use --keep to retain)\n"},
+		{1, false, "Removed 1 changeset file (use --keep to retain)\n"},
+		{2, true, "Kept 2 changeset files (--keep)\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		printCleanupSummary(&buf, tt.n, tt.kept)
+		if buf.String() != tt.want {
+			t.Errorf("printCleanupSummary(%d, %v) = %q, want %q", tt.n, tt.kept, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestCmdReleaseChangesOutsideRoot(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "keep.md"), []byte("---\ntest: patch\n---\n\nFix"), 0644)
+
+	os.RemoveAll(p.changes)
+	if err := os.Symlink(outside, p.changes); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err == nil {
+		t.Fatal("expected error when changes directory is outside the project")
+	}
+	if _, statErr := os.Stat(filepath.Join(outside, "keep.md")); statErr != nil {
+		t.Error("changeset outside the project must not be removed")
+	}
+}
+
+func TestCmdReleaseJSON(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFixed bug",
+		"---\ntest: minor\n---\n\nAdded feature",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{json: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+
+	var result releaseResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, output)
+	}
+	if result.Previous != "v1.0.0" {
+		t.Errorf("expected previous v1.0.0, got %s", result.Previous)
+	}
+	if result.Version != "v1.1.0" {
+		t.Errorf("expected version v1.1.0, got %s", result.Version)
+	}
+	if result.ChangelogPath != "CHANGELOG.md" {
+		t.Errorf("expected changelogPath CHANGELOG.md, got %s", result.ChangelogPath)
+	}
+	if result.Consumed != 2 {
+		t.Errorf("expected 2 consumed changesets, got %d", result.Consumed)
+	}
+}
+
+func TestCmdReleaseSingleSection(t *testing.T) {
+	p := setupProject(t, "v1.4.2",
+		"---\ntest: patch\n---\n\nFixed bug",
+		"---\ntest: minor\n---\n\nAdded feature",
+		"---\ntest: major\n---\n\nRemoved old API",
+		"---\ntest: minor\n---\n\nAdded another feature",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	// The highest bump is applied once, not once per changeset.
+	if strings.TrimSpace(output) != "v2.0.0" {
+		t.Errorf("expected v2.0.0, got %q", strings.TrimSpace(output))
+	}
+
+	data, _ := os.ReadFile(filepath.Join(p.root, "CHANGELOG.md"))
+	if n := strings.Count(string(data), "\n## "); n != 1 {
+		t.Errorf("expected a single release section, found %d:\n%s", n, data)
+	}
+}
+
+func TestCmdReleaseCompareLinks(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded feature")
+	saveConfig(p.config, &config{Version: "v1.0.0", RepoURL: "https://github.com/o/r", CompareLinks: true})
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(p.root, "CHANGELOG.md"))
+	content := string(data)
+	if !strings.Contains(content, "## [v1.1.0] - ") {
+		t.Errorf("expected bracketed header, got:\n%s", content)
+	}
+	if !strings.HasSuffix(content, "[v1.1.0]: https://github.com/o/r/compare/v1.0.0...v1.1.0\n") {
+		t.Errorf("expected compare link at the bottom, got:\n%s", content)
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.PreviousVersion != "v1.0.0" {
+		t.Errorf("expected previous version v1.0.0 in config, got %q", cfg.PreviousVersion)
+	}
+}
+
+func TestBuildChangelogSectionCompareURL(t *testing.T) {
+	changes := []*changeset{{filepath: "/nonexistent/a.md", bump: patch, summary: "Fix"}}
+	tmpl := "{{.PreviousVersion}} {{.CompareURL}}"
+
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{template: tmpl, repoURL: "https://github.com/o/r", previousVersion: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if result != "v1.0.0 https://github.com/o/r/compare/v1.0.0...v1.0.1" {
+		t.Errorf("unexpected compare URL rendering %q", result)
+	}
+
+	result, _ = buildChangelogSection("v1.0.1", changes, changelogOptions{template: tmpl, repoURL: "https://github.com/o/r"})
+	if result != " " {
+		t.Errorf("expected empty values without a previous version, got %q", result)
+	}
+}
+
+func TestBuildChangelogSectionInMemory(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "a.md", bump: minor, summary: "New feature"},
+		{filepath: "b.md", bump: patch, summary: "Bug fix"},
+	}
+	shas := map[string]string{"a.md": "a1b2c3d"}
+	opts := changelogOptions{
+		date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		commitSHA: func(path string) (string, error) {
+			return shas[path], nil
+		},
+	}
+
+	result, err := buildChangelogSection("v1.1.0", changes, opts)
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	want := "## v1.1.0 - 2024-03-01\n\n### Minor Changes\n\n- a1b2c3d: New feature\n\n### Patch Changes\n\n- Bug fix\n"
+	if result != want {
+		t.Errorf("buildChangelogSection = %q, expected %q", result, want)
+	}
+}
+
+func TestBuildChangelogSectionBullets(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "a.md", bump: minor, summary: "- Added --json\n- Added --pretty", details: "Both apply to status."},
+		{filepath: "b.md", bump: patch, summary: "Bug fix"},
+	}
+	opts := changelogOptions{
+		date:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		commitSHA: func(string) (string, error) { return "a1b2c3d", nil },
+	}
+
+	result, err := buildChangelogSection("v1.1.0", changes, opts)
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	want := "## v1.1.0 - 2024-03-01\n\n### Minor Changes\n\n- a1b2c3d: Added --json\n  Both apply to status.\n- a1b2c3d: Added --pretty\n\n### Patch Changes\n\n- a1b2c3d: Bug fix\n"
+	if result != want {
+		t.Errorf("buildChangelogSection = %q, expected %q", result, want)
+	}
+}
+
+func TestBuildChangelogSectionCommitSubject(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "a.md", bump: patch, summary: "Fix crash", author: "@me"},
+		{filepath: "b.md", bump: patch, summary: "Fix typo"},
+	}
+	subjects := map[string]string{"a.md": "Handle nil config"}
+	opts := changelogOptions{
+		date:          time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		showAuthors:   true,
+		commitSHA:     func(string) (string, error) { return "", nil },
+		commitSubject: func(path string) (string, error) { return subjects[path], nil },
+	}
+
+	result, err := buildChangelogSection("v1.0.1", changes, opts)
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if strings.Contains(result, "Handle nil config") {
+		t.Errorf("expected no subject without showSubjects, got %q", result)
+	}
+
+	opts.showSubjects = true
+	result, _ = buildChangelogSection("v1.0.1", changes, opts)
+	if !strings.Contains(result, "- Fix crash — \"Handle nil config\" (@me)\n") {
+		t.Errorf("expected the commit subject after the summary, got %q", result)
 	}
-	if !strings.Contains(string(data), "v1.0.1") {
-		t.Error("CHANGELOG.md missing version")
+	if !strings.Contains(result, "- Fix typo\n") {
+		t.Errorf("expected an uncommitted changeset without a subject, got %q", result)
+	}
+}
+
+func TestCmdReleaseNoneBump(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFixed bug",
+		"---\ntest: none\n---\n\nClarified the install docs",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{date: "2024-03-01"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v1.0.1" {
+		t.Errorf("expected the none changeset not to raise the bump, got %q", output)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(p.root, changelogFile))
+	if !strings.Contains(string(data), "### Patch Changes\n\n- Fixed bug\n\n### Other Changes\n\n- Clarified the install docs\n") {
+		t.Errorf("expected the note under Other Changes, got:\n%s", data)
+	}
+}
+
+func TestCmdReleaseOnlyNoneBumps(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: none\n---\n\nClarified the install docs")
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "--allow-empty") {
+		t.Fatalf("expected an error suggesting --allow-empty, got %v", err)
+	}
+
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{allowEmpty: true, quiet: true})
+	})
+	if err != nil || output != "" {
+		t.Fatalf("expected --allow-empty to succeed silently, got %q, %v", output, err)
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.0" {
+		t.Errorf("expected the version to stay v1.0.0, got %s", cfg.Version)
+	}
+	if changes, _ := listChangesets(p.changes, parseOptions{}); len(changes) != 1 {
+		t.Errorf("expected the note to stay pending, got %d changesets", len(changes))
+	}
+	if _, err := os.Stat(filepath.Join(p.root, changelogFile)); !os.IsNotExist(err) {
+		t.Error("expected no changelog to be written")
+	}
+}
+
+func TestCmdReleaseVersionOverride(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+	t.Setenv(releaseVersionEnv, "3.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{version: "v2.0.0"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v2.0.0" {
+		t.Errorf("expected --version to win over the env var, got %q", output)
+	}
+
+	p = setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+	output = captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if strings.TrimSpace(output) != "v3.0.0" {
+		t.Errorf("expected the env var to override the computed version, got %q", output)
+	}
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v3.0.0" {
+		t.Errorf("expected config version v3.0.0, got %q", cfg.Version)
+	}
+}
+
+func TestCmdReleaseVersionOverrideInvalid(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+	t.Setenv(releaseVersionEnv, "next")
+
+	err := cmdRelease(p, newScanner(""), releaseOptions{})
+	if !errors.Is(err, ErrInvalidVersion) || !strings.Contains(err.Error(), releaseVersionEnv) {
+		t.Errorf("expected an invalid version error naming %s, got %v", releaseVersionEnv, err)
+	}
+	if _, statErr := os.Stat(filepath.Join(p.root, changelogFile)); !os.IsNotExist(statErr) {
+		t.Error("nothing should be written for an invalid override")
+	}
+
+	if err := cmdRelease(p, newScanner(""), releaseOptions{version: "v2.0.0", channel: "beta"}); err == nil {
+		t.Error("expected --version with --channel to be rejected")
+	}
+}
+
+func TestCmdReleaseDryRun(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded feature")
+	changelogPath := filepath.Join(p.root, changelogFile)
+	os.WriteFile(changelogPath, []byte("# Changelog\n\n## v1.0.0 - 2024-01-01\n\n### Major Changes\n\n- Initial release\n"), 0644)
+	before, _ := os.ReadFile(changelogPath)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{dryRun: true, date: "2024-03-01"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if !strings.HasPrefix(output, "Next version: v1.1.0\n\n## v1.1.0 - 2024-03-01\n") {
+		t.Errorf("expected the version and section, got:\n%s", output)
+	}
+
+	output = captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{dryRun: true, diff: true, date: "2024-03-01"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	for _, want := range []string{
+		"--- a/CHANGELOG.md\n+++ b/CHANGELOG.md\n@@ -1,5 +1,11 @@\n # Changelog\n \n+## v1.1.0 - 2024-03-01\n",
+		"--- a/.changesets/config.json\n+++ b/.changesets/config.json\n",
+		"-  \"version\": \"v1.0.0\"\n+  \"version\": \"v1.1.0\",\n+  \"previousVersion\": \"v1.0.0\"\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in the diff, got:\n%s", want, output)
+		}
+	}
+
+	after, _ := os.ReadFile(changelogPath)
+	cfg, _ := loadConfig(p.config)
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if string(after) != string(before) || cfg.Version != "v1.0.0" || len(changes) != 1 {
+		t.Error("a dry run must not write anything")
+	}
+}
+
+func TestParseReleaseFlagsDryRun(t *testing.T) {
+	if _, err := parseReleaseFlags([]string{"--diff"}); err == nil {
+		t.Error("expected --diff without --dry-run to be rejected")
+	}
+	if _, err := parseReleaseFlags([]string{"--dry-run", "--all"}); err == nil {
+		t.Error("expected --dry-run with --all to be rejected")
+	}
+	opts, err := parseReleaseFlags([]string{"--dry-run", "--diff"})
+	if err != nil || !opts.dryRun || !opts.diff {
+		t.Errorf("expected --dry-run --diff to parse, got %+v (%v)", opts, err)
+	}
+}
+
+func TestCmdReleaseStepSummary(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+	summary := filepath.Join(t.TempDir(), "step_summary.md")
+	os.WriteFile(summary, []byte("# Build\n"), 0644)
+	t.Setenv(stepSummaryEnv, summary)
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{date: "2024-03-01"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(summary)
+	if want := "# Build\n## v1.0.1 - 2024-03-01\n\n### Patch Changes\n\n- Fixed bug\n\n"; string(data) != want {
+		t.Errorf("step summary = %q, expected %q", data, want)
+	}
+}
+
+func TestAppendStepSummaryUnset(t *testing.T) {
+	t.Setenv(stepSummaryEnv, "")
+	if err := appendStepSummary("## v1.0.0\n"); err != nil {
+		t.Errorf("expected no error without %s, got %v", stepSummaryEnv, err)
+	}
+}
+
+func TestCmdReleaseCompareLinksWithoutRepoURL(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded feature")
+	saveConfig(p.config, &config{Version: "v1.0.0", CompareLinks: true})
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(p.root, "CHANGELOG.md"))
+	if strings.Contains(string(data), "[v1.1.0]") {
+		t.Errorf("expected no compare links without repoURL, got:\n%s", data)
+	}
+}
+
+func TestCmdReleaseInteractiveConfirm(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner("y\n"), releaseOptions{interactive: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if !strings.Contains(output, "Next version: v1.0.1") {
+		t.Error("expected preview to show the next version")
+	}
+	if !strings.Contains(output, "- Fixed bug") {
+		t.Error("expected preview to show the changelog section")
 	}
 
 	cfg, _ := loadConfig(p.config)
 	if cfg.Version != "v1.0.1" {
 		t.Errorf("expected config v1.0.1, got %s", cfg.Version)
 	}
+}
 
-	entries, _ := os.ReadDir(p.changes)
-	for _, e := range entries {
-		if strings.HasSuffix(e.Name(), ".md") {
-			t.Errorf("changeset %s should be removed", e.Name())
+func TestCmdReleaseInteractiveAbort(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner("n\n"), releaseOptions{interactive: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease should not error on abort: %v", err)
+	}
+	if !strings.Contains(output, "Aborted") {
+		t.Error("expected 'Aborted' message")
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.0" {
+		t.Errorf("expected config to stay v1.0.0, got %s", cfg.Version)
+	}
+	if _, statErr := os.Stat(filepath.Join(p.root, "CHANGELOG.md")); !os.IsNotExist(statErr) {
+		t.Error("CHANGELOG.md should not be written on abort")
+	}
+	if _, statErr := os.Stat(filepath.Join(p.changes, "change-0.md")); statErr != nil {
+		t.Error("changeset should be kept on abort")
+	}
+}
+
+func TestCmdReleaseInteractiveNoInput(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{interactive: true})
+	})
+	if err == nil {
+		t.Fatal("expected error for no input on confirmation")
+	}
+}
+
+func TestParseReleaseFlags(t *testing.T) {
+	opts, err := parseReleaseFlags([]string{"--json"})
+	if err != nil {
+		t.Fatalf("parseReleaseFlags failed: %v", err)
+	}
+	if !opts.json {
+		t.Error("expected json to be set")
+	}
+
+	opts, err = parseReleaseFlags(nil)
+	if err != nil {
+		t.Fatalf("parseReleaseFlags failed: %v", err)
+	}
+	if opts.json {
+		t.Error("expected json to default to false")
+	}
+
+	opts, err = parseReleaseFlags([]string{"--package", "api"})
+	if err != nil {
+		t.Fatalf("parseReleaseFlags failed: %v", err)
+	}
+	if opts.pkg != "api" {
+		t.Errorf("expected package api, got %q", opts.pkg)
+	}
+}
+
+func TestParseReleaseFlagsDate(t *testing.T) {
+	opts, err := parseReleaseFlags([]string{"--date", "2024-01-15"})
+	if err != nil {
+		t.Fatalf("parseReleaseFlags failed: %v", err)
+	}
+	if opts.date != "2024-01-15" {
+		t.Errorf("expected date 2024-01-15, got %q", opts.date)
+	}
+
+	for _, bad := range []string{"2024-1-15", "15/01/2024", "2024-02-30"} {
+		if _, err := parseReleaseFlags([]string{"--date", bad}); err == nil {
+			t.Errorf("expected error for date %q", bad)
+		}
+	}
+}
+
+func TestParseReleaseFlagsPretty(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"--pretty"}, true},
+		{[]string{"--pretty=false"}, false},
+	}
+	for _, tt := range tests {
+		opts, err := parseReleaseFlags(tt.args)
+		if err != nil {
+			t.Fatalf("parseReleaseFlags(%v) failed: %v", tt.args, err)
 		}
+		// An absent flag falls back to the default, here true.
+		if got := opts.pretty.or(true); got != tt.want {
+			t.Errorf("parseReleaseFlags(%v): pretty = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	v := bumpCounts{Major: 1, Minor: 2, Patch: 3}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v, false); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+	if want := `{"major":1,"minor":2,"patch":3,"none":0}` + "\n"; buf.String() != want {
+		t.Errorf("compact: got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, v, true); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+	if want := "{\n  \"major\": 1,\n  \"minor\": 2,\n  \"patch\": 3,\n  \"none\": 0\n}\n"; buf.String() != want {
+		t.Errorf("pretty: got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseReleaseFlagsJSONToStdout(t *testing.T) {
+	if _, err := parseReleaseFlags([]string{"--json", "--output", "-"}); err == nil {
+		t.Fatal("expected error combining --json with --output -")
+	}
+}
+
+func TestParseReleaseFlagsUnknown(t *testing.T) {
+	if _, err := parseReleaseFlags([]string{"--bogus"}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
+}
+
+func TestRunReleaseInvalidFlag(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	os.Chdir(dir)
+
+	var code int
+	captureStdout(func() {
+		code = run([]string{"changesets", "release", "--bogus"}, strings.NewReader(""))
+	})
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
 	}
 }
 
@@ -646,16 +2174,57 @@ func TestCmdReleaseNoChangesets(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdRelease(p)
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error when no changesets")
 	}
 }
 
+func TestCmdReleaseQuiet(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{quiet: true})
+	})
+	if err != nil {
+		t.Fatalf("expected no error with --quiet and nothing to release, got %v", err)
+	}
+	if output != "" {
+		t.Errorf("expected no output, got %q", output)
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.0" {
+		t.Errorf("expected version to stay v1.0.0, got %s", cfg.Version)
+	}
+	if _, err := os.Stat(filepath.Join(p.root, "CHANGELOG.md")); !os.IsNotExist(err) {
+		t.Error("CHANGELOG.md should not be created")
+	}
+}
+
+func TestCmdReleaseNestedChangesets(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	nested := filepath.Join(p.changes, "backlog")
+	os.MkdirAll(nested, 0755)
+	os.WriteFile(filepath.Join(nested, "old.md"), []byte("---\ntest: patch\n---\n\nFix"), 0644)
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if !errors.Is(err, ErrNoChangesets) {
+		t.Fatalf("expected ErrNoChangesets, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 in subdirectories (e.g. backlog/old.md) are not read") {
+		t.Errorf("expected hint about nested changesets, got %q", err.Error())
+	}
+}
+
 func TestCmdReleaseNoDir(t *testing.T) {
 	p := newPaths(t.TempDir())
-	if err := cmdRelease(p); err == nil {
+	if err := cmdRelease(p, newScanner(""), releaseOptions{}); err == nil {
 		t.Fatal("expected error when .changesets doesn't exist")
 	}
 }
@@ -666,7 +2235,7 @@ func TestCmdReleaseCalculateError(t *testing.T) {
 	os.MkdirAll(p.changesets, 0755)
 	os.MkdirAll(p.changes, 0755)
 
-	err := cmdRelease(p)
+	err := cmdRelease(p, newScanner(""), releaseOptions{})
 	if err == nil {
 		t.Fatal("expected error when config is missing")
 	}
@@ -675,7 +2244,7 @@ func TestCmdReleaseCalculateError(t *testing.T) {
 func TestCalculateNextVersionPatch(t *testing.T) {
 	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
 
-	ver, changes, cfg, err := calculateNextVersion(p)
+	ver, changes, cfg, err := calculateNextVersion(p, false, false, time.Time{})
 	if err != nil {
 		t.Fatalf("failed: %v", err)
 	}
@@ -685,15 +2254,93 @@ func TestCalculateNextVersionPatch(t *testing.T) {
 	if len(changes) != 1 {
 		t.Errorf("expected 1 changeset, got %d", len(changes))
 	}
-	if cfg == nil {
-		t.Error("expected non-nil config")
+	if cfg == nil {
+		t.Error("expected non-nil config")
+	}
+}
+
+func TestCalculateNextVersionCalver(t *testing.T) {
+	p := setupProject(t, "v0.0.0", "---\ntest: major\n---\n\nBreaking")
+	if err := saveConfig(p.config, &config{Version: "v2020.1.3", VersioningScheme: schemeCalver}); err != nil {
+		t.Fatal(err)
+	}
+
+	ver, _, _, err := calculateNextVersion(p, false, false, time.Time{})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	now := time.Now()
+	if want := fmt.Sprintf("v%d.%d.0", now.Year(), now.Month()); ver != want {
+		t.Errorf("expected %s, got %s", want, ver)
+	}
+}
+
+func TestCmdReleaseCalverDate(t *testing.T) {
+	p := setupProject(t, "v0.0.0", "---\ntest: patch\n---\n\nFix")
+	if err := saveConfig(p.config, &config{Version: "v2023.12.4", VersioningScheme: schemeCalver}); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{allowDirty: true, date: "2024-01-15", output: "-"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if !strings.HasPrefix(output, "## v2024.1.0 - 2024-01-15\n") {
+		t.Errorf("expected the version and header to follow --date, got %q", output)
+	}
+}
+
+func TestCalculateNextVersionUnknownScheme(t *testing.T) {
+	p := setupProject(t, "v0.0.0", "---\ntest: patch\n---\n\nFix")
+	if err := saveConfig(p.config, &config{Version: "v1.0.0", VersioningScheme: "romver"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := calculateNextVersion(p, false, false, time.Time{}); err == nil {
+		t.Fatal("expected error for unknown versioning scheme")
+	}
+}
+
+func TestCalculateNextVersionPrereleaseBump(t *testing.T) {
+	tests := []struct {
+		current string
+		bump    bumpType
+		allow   bool
+		want    string
+	}{
+		{"v1.2.0-rc.1", patch, false, "v1.2.0-rc.2"},
+		{"v1.2.0-rc.1", minor, false, "v1.3.0"},
+		{"v1.2.0-rc.1", major, false, "v2.0.0"},
+		{"v1.2.0-rc.1", patch, true, "v1.2.0-rc.2"},
+		{"v1.2.0-rc.1", minor, true, "v1.2.0-rc.2"},
+		{"v1.2.0-rc.1", major, true, "v1.2.0-rc.2"},
+		{"v1.2.0", patch, false, "v1.2.1"},
+		{"v1.2.0", minor, false, "v1.3.0"},
+		{"v1.2.0", major, false, "v2.0.0"},
+		{"v1.2.0", patch, true, "v1.2.1"},
+		{"v1.2.0", minor, true, "v1.3.0"},
+		{"v1.2.0", major, true, "v2.0.0"},
+	}
+
+	for _, tt := range tests {
+		p := setupProject(t, tt.current, "---\ntest: "+string(tt.bump)+"\n---\n\nChange")
+		ver, _, _, err := calculateNextVersion(p, false, tt.allow, time.Time{})
+		if err != nil {
+			t.Fatalf("calculateNextVersion(%s, %s, %v) failed: %v", tt.current, tt.bump, tt.allow, err)
+		}
+		if ver != tt.want {
+			t.Errorf("calculateNextVersion(%s, %s, %v) = %s, want %s", tt.current, tt.bump, tt.allow, ver, tt.want)
+		}
 	}
 }
 
 func TestCalculateNextVersionMinor(t *testing.T) {
 	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nFeat")
 
-	ver, _, _, err := calculateNextVersion(p)
+	ver, _, _, err := calculateNextVersion(p, false, false, time.Time{})
 	if err != nil {
 		t.Fatalf("failed: %v", err)
 	}
@@ -705,7 +2352,7 @@ func TestCalculateNextVersionMinor(t *testing.T) {
 func TestCalculateNextVersionMajor(t *testing.T) {
 	p := setupProject(t, "v1.0.0", "---\ntest: major\n---\n\nBreaking")
 
-	ver, _, _, err := calculateNextVersion(p)
+	ver, _, _, err := calculateNextVersion(p, false, false, time.Time{})
 	if err != nil {
 		t.Fatalf("failed: %v", err)
 	}
@@ -714,10 +2361,25 @@ func TestCalculateNextVersionMajor(t *testing.T) {
 	}
 }
 
+func TestCalculateNextVersionPrerelease(t *testing.T) {
+	p := setupProject(t, "v1.2.0-rc.1",
+		"---\ntest: patch\n---\n\nFix one",
+		"---\ntest: patch\n---\n\nFix two",
+	)
+
+	ver, _, _, err := calculateNextVersion(p, false, false, time.Time{})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if ver != "v1.2.0-rc.2" {
+		t.Errorf("expected v1.2.0-rc.2, got %s", ver)
+	}
+}
+
 func TestCalculateNextVersionNoChangesets(t *testing.T) {
 	p := setupProject(t, "v1.0.0")
 
-	ver, changes, cfg, err := calculateNextVersion(p)
+	ver, changes, cfg, err := calculateNextVersion(p, false, false, time.Time{})
 	if err != nil {
 		t.Fatalf("failed: %v", err)
 	}
@@ -735,7 +2397,7 @@ func TestCalculateNextVersionNoChangesets(t *testing.T) {
 func TestCalculateNextVersionInvalidVersion(t *testing.T) {
 	p := setupProject(t, "not-a-version", "---\ntest: patch\n---\n\nFix")
 
-	_, _, _, err := calculateNextVersion(p)
+	_, _, _, err := calculateNextVersion(p, false, false, time.Time{})
 	if err == nil {
 		t.Fatal("expected error for invalid version")
 	}
@@ -746,7 +2408,7 @@ func TestCalculateNextVersionConfigMissing(t *testing.T) {
 	p := newPaths(dir)
 	os.MkdirAll(p.changes, 0755)
 
-	_, _, _, err := calculateNextVersion(p)
+	_, _, _, err := calculateNextVersion(p, false, false, time.Time{})
 	if err == nil {
 		t.Fatal("expected error when config missing")
 	}
@@ -758,7 +2420,7 @@ func TestCalculateNextVersionListError(t *testing.T) {
 	os.MkdirAll(p.changesets, 0755)
 	saveConfig(p.config, &config{Version: "v1.0.0"})
 
-	_, _, _, err := calculateNextVersion(p)
+	_, _, _, err := calculateNextVersion(p, false, false, time.Time{})
 	if err == nil {
 		t.Fatal("expected error when changes dir missing")
 	}
@@ -771,7 +2433,10 @@ func TestBuildChangelogSection(t *testing.T) {
 		{filepath: "test3.md", bump: patch, summary: "Bug fix"},
 	}
 
-	result := buildChangelogSection("v2.0.0", changes)
+	result, err := buildChangelogSection("v2.0.0", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
 
 	if !strings.Contains(result, "## v2.0.0") {
 		t.Error("missing version header")
@@ -796,12 +2461,30 @@ func TestBuildChangelogSection(t *testing.T) {
 	}
 }
 
+func TestBuildChangelogSectionCompareLinks(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/nonexistent/file.md", bump: patch, summary: "Fix"},
+	}
+
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{compareLinks: true})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+
+	if !strings.HasPrefix(result, "## [v1.0.1] - ") {
+		t.Errorf("expected bracketed version header, got %q", result)
+	}
+}
+
 func TestBuildChangelogSectionEmptyGroups(t *testing.T) {
 	changes := []*changeset{
 		{filepath: "test.md", bump: patch, summary: "Fix"},
 	}
 
-	result := buildChangelogSection("v1.0.1", changes)
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
 
 	if strings.Contains(result, "Major Changes") {
 		t.Error("should not have Major Changes")
@@ -829,7 +2512,10 @@ func TestBuildChangelogSectionWithSHA(t *testing.T) {
 		{filepath: "change.md", bump: patch, summary: "Updated deps"},
 	}
 
-	result := buildChangelogSection("v1.0.1", changes)
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
 
 	if !strings.Contains(result, ": Updated deps") {
 		t.Error("expected SHA-prefixed entry for git-tracked file")
@@ -841,17 +2527,226 @@ func TestBuildChangelogSectionWithoutSHA(t *testing.T) {
 		{filepath: "/nonexistent/file.md", bump: patch, summary: "Fix"},
 	}
 
-	result := buildChangelogSection("v1.0.1", changes)
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
 
 	if !strings.Contains(result, "- Fix\n") {
 		t.Error("expected plain entry without SHA for non-tracked file")
 	}
 }
 
+func TestBuildChangelogSectionFormat(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/nonexistent/a.md", bump: patch, summary: "Fix"},
+		{filepath: "/nonexistent/b.md", bump: minor, summary: "Feature"},
+	}
+
+	result, err := buildChangelogSection("v1.1.0", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+
+	date := time.Now().Format("2006-01-02")
+	expected := "## v1.1.0 - " + date + "\n\n### Minor Changes\n\n- Feature\n\n### Patch Changes\n\n- Fix\n"
+	if result != expected {
+		t.Errorf("unexpected section.\nExpected:\n%q\nGot:\n%q", expected, result)
+	}
+}
+
+func TestBuildChangelogSectionDate(t *testing.T) {
+	changes := []*changeset{{filepath: "/nonexistent/a.md", bump: patch, summary: "Fix"}}
+	date := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{date: date})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "## v1.0.1 - 2024-01-15\n") {
+		t.Errorf("expected the given date in the header, got:\n%s", result)
+	}
+}
+
+func TestCmdReleaseDate(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{date: "2024-01-15"}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+
+	data, _ := os.ReadFile(filepath.Join(p.root, changelogFile))
+	if !strings.Contains(string(data), "## v1.0.1 - 2024-01-15") {
+		t.Errorf("expected the release date in CHANGELOG.md, got:\n%s", data)
+	}
+}
+
+func TestBuildChangelogSectionCustomTemplate(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/nonexistent/a.md", bump: patch, summary: "Fix"},
+		{filepath: "/nonexistent/b.md", bump: major, summary: "Rewrite"},
+	}
+	tmpl := "Release {{.Version}}\n{{range .Groups}}{{range .Entries}}* [{{$.Version}}/{{.Summary}}]\n{{end}}{{end}}"
+
+	result, err := buildChangelogSection("v2.0.0", changes, changelogOptions{template: tmpl})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if result != "Release v2.0.0\n* [v2.0.0/Rewrite]\n* [v2.0.0/Fix]\n" {
+		t.Errorf("unexpected custom section %q", result)
+	}
+}
+
+func TestBuildChangelogSectionAuthors(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/nonexistent/a.md", bump: patch, summary: "Fix", author: "@alice"},
+		{filepath: "/nonexistent/b.md", bump: patch, summary: "Other fix"},
+	}
+
+	result, err := buildChangelogSection("v1.0.1", changes, changelogOptions{showAuthors: true})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if !strings.Contains(result, "- Fix (@alice)\n") || !strings.Contains(result, "- Other fix\n") {
+		t.Errorf("expected author credit only where set, got:\n%s", result)
+	}
+
+	result, _ = buildChangelogSection("v1.0.1", changes, changelogOptions{})
+	if strings.Contains(result, "@alice") {
+		t.Errorf("authors should be hidden unless enabled, got:\n%s", result)
+	}
+}
+
+func TestBuildChangelogSectionDetails(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/nonexistent/a.md", bump: minor, summary: "Renamed --out", details: "Old flag still works.\n\nIt prints a warning."},
+	}
+
+	result, err := buildChangelogSection("v1.1.0", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	want := "- Renamed --out\n  Old flag still works.\n\n  It prints a warning.\n"
+	if !strings.Contains(result, want) {
+		t.Errorf("expected indented details, got:\n%s", result)
+	}
+}
+
+func TestBuildChangelogSectionCollapse(t *testing.T) {
+	changes := []*changeset{
+		{filepath: "/nonexistent/a.md", bump: patch, summary: "Bump x/net", collapse: "Dependency updates"},
+		{filepath: "/nonexistent/b.md", bump: patch, summary: "Fixed a crash"},
+		{filepath: "/nonexistent/c.md", bump: patch, summary: "Bump semver", collapse: "Dependency updates"},
+		{filepath: "/nonexistent/d.md", bump: minor, summary: "Bump Go", collapse: "Dependency updates"},
+	}
+
+	result, err := buildChangelogSection("v1.1.0", changes, changelogOptions{})
+	if err != nil {
+		t.Fatalf("buildChangelogSection failed: %v", err)
+	}
+	if !strings.Contains(result, "### Patch Changes\n\n- Fixed a crash\n- Dependency updates (2)\n") {
+		t.Errorf("expected collapsed patch entries after the listed ones, got:\n%s", result)
+	}
+	if !strings.Contains(result, "### Minor Changes\n\n- Dependency updates (1)\n") {
+		t.Errorf("expected collapsed entries to stay in their bump group, got:\n%s", result)
+	}
+	if strings.Contains(result, "Bump") {
+		t.Errorf("collapsed summaries should not be listed, got:\n%s", result)
+	}
+
+	if highestBump(changes) != minor {
+		t.Error("collapsed changesets should still count towards the bump")
+	}
+}
+
+func TestBuildChangelogSectionInvalidTemplate(t *testing.T) {
+	if _, err := buildChangelogSection("v1.0.0", nil, changelogOptions{template: "{{.Version"}); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := buildChangelogSection("v1.0.0", nil, changelogOptions{template: "{{.Missing}}"}); err == nil {
+		t.Error("expected execution error for unknown field")
+	}
+}
+
+func TestLoadChangelogTemplate(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "config.tmpl"), []byte("from config"), 0644)
+	flagPath := filepath.Join(root, "flag.tmpl")
+	os.WriteFile(flagPath, []byte("from flag"), 0644)
+
+	if got, err := loadChangelogTemplate(root, &config{}, ""); err != nil || got != "" {
+		t.Errorf("expected built-in template, got %q (%v)", got, err)
+	}
+
+	cfg := &config{ChangelogTemplate: "config.tmpl"}
+	if got, _ := loadChangelogTemplate(root, cfg, ""); got != "from config" {
+		t.Errorf("expected config template, got %q", got)
+	}
+	if got, _ := loadChangelogTemplate(root, cfg, flagPath); got != "from flag" {
+		t.Errorf("expected flag to win over config, got %q", got)
+	}
+
+	if _, err := loadChangelogTemplate(root, &config{ChangelogTemplate: "missing.tmpl"}, ""); err == nil {
+		t.Error("expected error for missing template file")
+	}
+}
+
+func TestCmdReleaseTemplateToStdout(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nFeature")
+	tmplPath := filepath.Join(p.root, "notes.tmpl")
+	os.WriteFile(tmplPath, []byte("Notes for {{.Version}}\n"), 0644)
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{templateFile: tmplPath, output: "-"}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+
+	if output != "Notes for v1.1.0\n" {
+		t.Errorf("expected only the rendered notes on stdout, got %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(p.root, changelogFile)); !os.IsNotExist(err) {
+		t.Error("CHANGELOG.md should not be written with --output")
+	}
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.0" {
+		t.Errorf("expected --output to leave the version at v1.0.0, got %s", cfg.Version)
+	}
+}
+
+func TestCmdReleaseOutputFile(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	notes := filepath.Join(p.root, "notes.md")
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{output: notes}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "v1.0.1" {
+		t.Errorf("expected version output, got %q", output)
+	}
+
+	data, err := os.ReadFile(notes)
+	if err != nil || !strings.Contains(string(data), "## v1.0.1") {
+		t.Errorf("expected section in %s, got %q (%v)", notes, data, err)
+	}
+	cfg, _ := loadConfig(p.config)
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if cfg.Version != "v1.0.0" || len(changes) != 1 {
+		t.Errorf("expected --output to release nothing, got version %s and %d pending changesets", cfg.Version, len(changes))
+	}
+	if _, err := parseReleaseFlags([]string{"--output", "-", "--manifest"}); err == nil {
+		t.Error("expected --output with --manifest to be rejected")
+	}
+}
+
 func TestPrependChangelogNewFile(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
 
-	if err := prependChangelog(path, "## v1.0.0\n\n- Fix\n"); err != nil {
+	if err := prependChangelog(path, "## v1.0.0\n\n- Fix\n", "", false, 0); err != nil {
 		t.Fatalf("failed: %v", err)
 	}
 
@@ -864,11 +2759,51 @@ func TestPrependChangelogNewFile(t *testing.T) {
 	}
 }
 
+func TestPrependChangelogMaxSections(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CHANGELOG.md")
+
+	for _, section := range []string{"## v1.0.0\n\n- First\n", "## v1.1.0\n\n- Second\n", "## v1.2.0\n\n- Third\n"} {
+		if err := prependChangelog(path, section, "", false, 2); err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	if expected := "# Changelog\n\n## v1.2.0\n\n- Third\n\n## v1.1.0\n\n- Second\n"; string(data) != expected {
+		t.Errorf("unexpected changelog.\nExpected:\n%q\nGot:\n%q", expected, data)
+	}
+	archive, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.archive.md"))
+	if err != nil {
+		t.Fatalf("expected an archive file: %v", err)
+	}
+	if expected := "# Changelog Archive\n\n## v1.0.0\n\n- First\n"; string(archive) != expected {
+		t.Errorf("unexpected archive.\nExpected:\n%q\nGot:\n%q", expected, archive)
+	}
+}
+
+func TestPrependChangelogHeading(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+
+	if err := prependChangelog(path, "## v1.0.0\n\n- Fix\n", "# Release Notes", false, 0); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	if err := prependChangelog(path, "## v1.1.0\n\n- New\n", "# Other", false, 0); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "# Release Notes\n\n## v1.1.0\n\n- New\n\n## v1.0.0\n\n- Fix\n"
+	if string(data) != expected {
+		t.Errorf("expected the heading of the new file to be kept.\nExpected:\n%q\nGot:\n%q", expected, data)
+	}
+}
+
 func TestPrependChangelogExistingWithHeader(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
 	os.WriteFile(path, []byte("# Changelog\n\n## v0.1.0\n\n- Old\n"), 0644)
 
-	if err := prependChangelog(path, "## v1.0.0\n\n- New\n"); err != nil {
+	if err := prependChangelog(path, "## v1.0.0\n\n- New\n", "", false, 0); err != nil {
 		t.Fatalf("failed: %v", err)
 	}
 
@@ -886,7 +2821,7 @@ func TestPrependChangelogHeaderNoNewline(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
 	os.WriteFile(path, []byte("# Changelog"), 0644)
 
-	if err := prependChangelog(path, "## v1.0.0\n"); err != nil {
+	if err := prependChangelog(path, "## v1.0.0\n", "", false, 0); err != nil {
 		t.Fatalf("failed: %v", err)
 	}
 
@@ -901,7 +2836,7 @@ func TestPrependChangelogNoHeader(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
 	os.WriteFile(path, []byte("existing content\n"), 0644)
 
-	if err := prependChangelog(path, "## v1.0.0\n"); err != nil {
+	if err := prependChangelog(path, "## v1.0.0\n", "", false, 0); err != nil {
 		t.Fatalf("failed: %v", err)
 	}
 
@@ -915,22 +2850,130 @@ func TestPrependChangelogNoHeader(t *testing.T) {
 	}
 }
 
+func TestPrependChangelogInsertMarker(t *testing.T) {
+	tests := []struct {
+		existing string
+		want     string
+	}{
+		{
+			"# Changelog\n\nHow to read this file.\n\n<!-- changesets:insert -->\n\n## v0.1.0\n\n- Old\n",
+			"# Changelog\n\nHow to read this file.\n\n<!-- changesets:insert -->\n\n## v1.0.0\n\n- New\n\n## v0.1.0\n\n- Old\n",
+		},
+		{
+			"---\ntitle: Changelog\n---\n<!-- changesets:insert -->",
+			"---\ntitle: Changelog\n---\n<!-- changesets:insert -->\n\n## v1.0.0\n\n- New\n",
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+		os.WriteFile(path, []byte(tt.existing), 0644)
+
+		if err := prependChangelog(path, "## v1.0.0\n\n- New\n", "", false, 0); err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+		data, _ := os.ReadFile(path)
+		if string(data) != tt.want {
+			t.Errorf("prependChangelog into %q = %q, expected %q", tt.existing, data, tt.want)
+		}
+	}
+}
+
 func TestPrependChangelogWriteError(t *testing.T) {
-	err := prependChangelog("/nonexistent/nested/CHANGELOG.md", "## v1.0.0\n")
+	err := prependChangelog("/nonexistent/nested/CHANGELOG.md", "## v1.0.0\n", "", false, 0)
 	if err == nil {
 		t.Fatal("expected error for unwritable path")
 	}
 }
 
+func TestPrependChangelogExistingVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	original := "# Changelog\n\n## v1.0.0 - 2026-01-01\n\n- Old\n"
+	os.WriteFile(path, []byte(original), 0644)
+
+	err := prependChangelog(path, "## v1.0.0 - 2026-01-02\n\n- New\n", "", false, 0)
+	if !errors.Is(err, ErrVersionReleased) {
+		t.Fatalf("expected ErrVersionReleased, got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != original {
+		t.Errorf("changelog should be untouched, got:\n%s", data)
+	}
+}
+
+func TestPrependChangelogReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	os.WriteFile(path, []byte("# Changelog\n\n## v1.1.0 - 2026-01-01\n\n- Old\n\n## v1.0.0 - 2025-01-01\n\n- First\n"), 0644)
+
+	if err := prependChangelog(path, "## v1.1.0 - 2026-01-02\n\n- New\n", "", true, 0); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "# Changelog\n\n## v1.1.0 - 2026-01-02\n\n- New\n\n## v1.0.0 - 2025-01-01\n\n- First\n"
+	if string(data) != expected {
+		t.Errorf("unexpected content.\nExpected:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestPrependChangelogReplaceLastSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	os.WriteFile(path, []byte("# Changelog\n\n## v1.0.0 - 2025-01-01\n\n- Old\n"), 0644)
+
+	if err := prependChangelog(path, "## v1.0.0 - 2026-01-02\n\n- New\n", "", true, 0); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "# Changelog\n\n## v1.0.0 - 2026-01-02\n\n- New\n"
+	if string(data) != expected {
+		t.Errorf("unexpected content.\nExpected:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestCmdReleaseExistingVersion(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	changelogPath := filepath.Join(p.root, changelogFile)
+	os.WriteFile(changelogPath, []byte("# Changelog\n\n## v1.0.1 - 2026-01-01\n\n- Fix\n"), 0644)
+
+	err := cmdRelease(p, newScanner(""), releaseOptions{})
+	if !errors.Is(err, ErrVersionReleased) {
+		t.Fatalf("expected ErrVersionReleased, got %v", err)
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.0" {
+		t.Errorf("config should be untouched, got %s", cfg.Version)
+	}
+	if entries, _ := os.ReadDir(p.changes); len(entries) != 2 {
+		t.Errorf("changesets should be kept, got %d entries", len(entries))
+	}
+
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{replace: true}); err != nil {
+			t.Fatalf("cmdRelease --replace failed: %v", err)
+		}
+	})
+	data, _ := os.ReadFile(changelogPath)
+	if strings.Count(string(data), "## v1.0.1") != 1 {
+		t.Errorf("expected a single v1.0.1 section, got:\n%s", data)
+	}
+}
+
 func TestCleanupChanges(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "one.md"), []byte("x"), 0644)
 	os.WriteFile(filepath.Join(dir, "two.md"), []byte("x"), 0644)
 	os.WriteFile(filepath.Join(dir, ".gitkeep"), []byte(""), 0644)
 
-	if err := cleanupChanges(dir); err != nil {
+	removed, err := cleanupChanges(dir)
+	if err != nil {
 		t.Fatalf("failed: %v", err)
 	}
+	if removed != 2 {
+		t.Errorf("expected 2 files removed, got %d", removed)
+	}
 
 	entries, _ := os.ReadDir(dir)
 	for _, e := range entries {
@@ -948,7 +2991,7 @@ func TestCleanupChangesWithSubdir(t *testing.T) {
 	os.Mkdir(filepath.Join(dir, "subdir"), 0755)
 	os.WriteFile(filepath.Join(dir, "test.md"), []byte("x"), 0644)
 
-	if err := cleanupChanges(dir); err != nil {
+	if _, err := cleanupChanges(dir); err != nil {
 		t.Fatalf("failed: %v", err)
 	}
 
@@ -958,12 +3001,53 @@ func TestCleanupChangesWithSubdir(t *testing.T) {
 }
 
 func TestCleanupChangesInvalidDir(t *testing.T) {
-	err := cleanupChanges("/nonexistent/dir")
+	_, err := cleanupChanges("/nonexistent/dir")
 	if err == nil {
 		t.Fatal("expected error for nonexistent directory")
 	}
 }
 
+func TestCmdReleaseCleanupPartiallyFails(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFixed bug",
+		"---\ntest: patch\n---\n\nFixed typo",
+	)
+	orig := removeFile
+	defer func() { removeFile = orig }()
+	removeFile = func(path string) error {
+		if filepath.Base(path) == "change-1.md" {
+			return os.ErrPermission
+		}
+		return os.Remove(path)
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "released v1.0.1, but these changesets are still pending: change-1.md") {
+		t.Fatalf("expected an error naming the leftover changeset, got %v", err)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected the removal error to be wrapped, got %v", err)
+	}
+}
+
+func TestCheckCleanup(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, readmeFile), []byte("# Changesets"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitkeep"), nil, 0644)
+	if err := checkCleanup(dir, "v1.0.1", nil); err != nil {
+		t.Errorf("expected no error without leftover changesets, got %v", err)
+	}
+
+	// A changeset that appears despite a successful cleanup is still caught.
+	os.WriteFile(filepath.Join(dir, "late.md"), []byte("---\ntest: patch\n---\n\nLate"), 0644)
+	if err := checkCleanup(dir, "v1.0.1", nil); err == nil || !strings.Contains(err.Error(), "late.md") {
+		t.Errorf("expected an error naming late.md, got %v", err)
+	}
+}
+
 func TestCleanupChangesRemoveError(t *testing.T) {
 	if os.Getuid() == 0 {
 		t.Skip("skipping: permission-based test requires non-root user")
@@ -973,7 +3057,7 @@ func TestCleanupChangesRemoveError(t *testing.T) {
 	os.Chmod(dir, 0555)
 	defer os.Chmod(dir, 0755)
 
-	err := cleanupChanges(dir)
+	_, err := cleanupChanges(dir)
 	if err == nil {
 		t.Fatal("expected error when file can't be removed")
 	}
@@ -991,9 +3075,72 @@ func TestCmdInitMkdirError(t *testing.T) {
 
 	var err error
 	captureStdout(func() {
-		err = cmdInit(p, newScanner(""))
+		err = cmdInit(p, newScanner(""), initOptions{})
 	})
 	if err == nil {
 		t.Fatal("expected error when parent dir is read-only")
 	}
 }
+
+func TestCmdConfigSchema(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdConfig(p, []string{"schema"})
+	})
+	if err != nil {
+		t.Fatalf("cmdConfig failed: %v", err)
+	}
+	if !strings.Contains(output, "version") {
+		t.Error("expected schema to list the version field")
+	}
+}
+
+func TestCmdConfigEffective(t *testing.T) {
+	p := setupProject(t, "v1.2.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdConfig(p, []string{"effective"})
+	})
+	if err != nil {
+		t.Fatalf("cmdConfig failed: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if got["version"] != "v1.2.0" {
+		t.Errorf("expected explicit version, got %v", got["version"])
+	}
+	if got["slugSeparator"] != defaultSlugSeparator {
+		t.Errorf("expected default slug separator, got %v", got["slugSeparator"])
+	}
+	if keywords, ok := got["bumpKeywords"].(map[string]any); !ok || keywords["breaking"] != "major" || len(keywords) != len(defaultBumpKeywords) {
+		t.Errorf("expected the built-in bump keywords, got %v", got["bumpKeywords"])
+	}
+	if labels, ok := got["bumpLabels"].(map[string]any); !ok || labels["semver:minor"] != "minor" || len(labels) != len(defaultBumpLabels) {
+		t.Errorf("expected the built-in bump labels, got %v", got["bumpLabels"])
+	}
+	for _, f := range configFields {
+		if _, ok := got[f.key]; !ok {
+			t.Errorf("effective config missing field %s", f.key)
+		}
+	}
+}
+
+func TestCmdConfigMissingSubcommand(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	if err := cmdConfig(p, nil); err == nil {
+		t.Fatal("expected error for missing subcommand")
+	}
+}
+
+func TestCmdConfigUnknownSubcommand(t *testing.T) {
+	p := setupProject(t, "v0.0.0")
+	if err := cmdConfig(p, []string{"bogus"}); err == nil {
+		t.Fatal("expected error for unknown subcommand")
+	}
+}
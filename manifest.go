@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// releasesDir is the directory under .changesets/ that release --manifest
+// writes manifests into.
+const releasesDir = "releases"
+
+// releaseManifest records exactly which changesets went into a release,
+// independent of the changelog prose, so it can be signed and verified.
+type releaseManifest struct {
+	Version         string          `json:"version"`
+	PreviousVersion string          `json:"previousVersion,omitempty"`
+	Channel         string          `json:"channel,omitempty"` // prerelease channel; empty for stable
+	Date            string          `json:"date"`
+	Changesets      []manifestEntry `json:"changesets"`
+}
+
+// manifestEntry describes a single consumed changeset.
+type manifestEntry struct {
+	Slug    string `json:"slug"`
+	Bump    string `json:"bump"`
+	Summary string `json:"summary"`
+	SHA     string `json:"sha,omitempty"` // short commit SHA that added the changeset; empty when unknown
+}
+
+// newReleaseManifest builds the manifest for releasing changes as ver, using
+// the same date, previous version and commit lookup as the changelog section.
+// Changesets are listed in changelog order: major, then minor, then patch.
+func newReleaseManifest(ver string, changes []*changeset, opts changelogOptions) releaseManifest {
+	date := opts.date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	m := releaseManifest{
+		Version:         ver,
+		PreviousVersion: opts.previousVersion,
+		Channel:         opts.channel,
+		Date:            date.Format(releaseDateLayout),
+		Changesets:      []manifestEntry{},
+	}
+
+	for _, bump := range []bumpType{major, minor, patch, none} {
+		for _, cs := range changes {
+			if cs.bump != bump {
+				continue
+			}
+			m.Changesets = append(m.Changesets, manifestEntry{
+				Slug:    cs.slug,
+				Bump:    string(cs.bump),
+				Summary: cs.summary,
+				SHA:     opts.entrySHA(cs),
+			})
+		}
+	}
+	return m
+}
+
+// writeReleaseManifest writes m to .changesets/releases/<version>.json and
+// returns the path relative to the project root.
+func writeReleaseManifest(p paths, m releaseManifest) (string, error) {
+	dir := filepath.Join(p.changesets, releasesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", releasesDir, err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, m, true); err != nil {
+		return "", fmt.Errorf("failed to encode release manifest: %w", err)
+	}
+	path := filepath.Join(dir, m.Version+".json")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write release manifest: %w", err)
+	}
+
+	rel, err := filepath.Rel(p.root, path)
+	if err != nil {
+		return path, nil
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdReleaseManifest(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFixed bug",
+		"---\ntest: minor\n---\n\nAdded feature",
+	)
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{manifest: true, date: "2024-03-01"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.changesets, releasesDir, "v1.1.0.json"))
+	if err != nil {
+		t.Fatalf("expected manifest to be written: %v", err)
+	}
+	var m releaseManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("invalid manifest JSON: %v", err)
+	}
+
+	if m.Version != "v1.1.0" || m.PreviousVersion != "v1.0.0" || m.Date != "2024-03-01" {
+		t.Errorf("unexpected manifest header %+v", m)
+	}
+	if len(m.Changesets) != 2 {
+		t.Fatalf("expected 2 changesets, got %d", len(m.Changesets))
+	}
+	if m.Changesets[0].Slug != "change-1" || m.Changesets[0].Bump != "minor" || m.Changesets[0].Summary != "Added feature" {
+		t.Errorf("expected the minor changeset first, got %+v", m.Changesets[0])
+	}
+	if m.Changesets[1].Slug != "change-0" || m.Changesets[1].Bump != "patch" {
+		t.Errorf("expected the patch changeset second, got %+v", m.Changesets[1])
+	}
+}
+
+func TestCmdReleaseNoManifest(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFixed bug")
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdRelease failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(p.changesets, releasesDir)); !os.IsNotExist(err) {
+		t.Error("releases directory should not be created without --manifest")
+	}
+}
+
+func TestParseReleaseFlagsManifestPackage(t *testing.T) {
+	if _, err := parseReleaseFlags([]string{"--manifest", "--package", "api"}); err == nil {
+		t.Error("expected --manifest with --package to be rejected")
+	}
+}
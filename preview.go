@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// previewVersion stands in for the version in previews rendered with a
+// template that has no "entry" template, since the real one is not known yet.
+const previewVersion = "Unreleased"
+
+// cmdPreview prints the changelog entry a pending changeset will produce,
+// rendered with the same template and options as release.
+func cmdPreview(p paths, args []string) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: changesets preview <slug>")
+	}
+	slug := filenameToSlug(args[0])
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(p.changes, slugToFilename(slug))
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("changeset %s not found in %s", slug, filepath.Base(p.changes))
+	}
+	cs, err := parseFile(path, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+
+	opts := cfg.changelogOptions()
+	if opts.template, err = loadChangelogTemplate(p.root, cfg, ""); err != nil {
+		return err
+	}
+
+	entry, err := renderChangelogEntry(cs, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Print(entry)
+	return nil
+}
+
+// renderChangelogEntry renders cs as it will appear in the changelog. Only the
+// "entry" template is executed when the changelog template defines one;
+// otherwise a whole section holding just cs is rendered.
+func renderChangelogEntry(cs *changeset, opts changelogOptions) (string, error) {
+	tmpl, err := parseChangelogTemplate(opts.template)
+	if err != nil {
+		return "", err
+	}
+
+	entryTmpl := tmpl.Lookup(entryTemplate)
+	if entryTmpl == nil {
+		return buildChangelogSection(previewVersion, []*changeset{cs}, opts)
+	}
+
+	data := newChangelogData(previewVersion, time.Now(), []*changeset{cs}, opts)
+	var sb strings.Builder
+	for _, g := range data.Groups {
+		for _, e := range g.Entries {
+			if err := entryTmpl.Execute(&sb, e); err != nil {
+				return "", fmt.Errorf("failed to render changelog template: %w", err)
+			}
+		}
+	}
+	return sb.String(), nil
+}
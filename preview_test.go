@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCmdPreview(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: minor\n---\n\nAdded feature")
+
+	for _, arg := range []string{"change-0", "change-0.md"} {
+		var err error
+		output := captureStdout(func() {
+			err = cmdPreview(p, []string{arg})
+		})
+		if err != nil {
+			t.Fatalf("cmdPreview(%s) failed: %v", arg, err)
+		}
+		if output != "- Added feature\n" {
+			t.Errorf("cmdPreview(%s) = %q, want %q", arg, output, "- Added feature\n")
+		}
+	}
+}
+
+func TestCmdPreviewNotFound(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	err := cmdPreview(p, []string{"missing"})
+	if err == nil || !strings.Contains(err.Error(), "changeset missing not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRenderChangelogEntryWithoutEntryTemplate(t *testing.T) {
+	cs := &changeset{bump: patch, summary: "Fixed bug"}
+
+	got, err := renderChangelogEntry(cs, changelogOptions{template: "{{range .Groups}}{{range .Entries}}* {{.Summary}}\n{{end}}{{end}}"})
+	if err != nil {
+		t.Fatalf("renderChangelogEntry failed: %v", err)
+	}
+	if got != "* Fixed bug\n" {
+		t.Errorf("got %q, want %q", got, "* Fixed bug\n")
+	}
+}
+
+func TestRenderChangelogEntryCustomEntry(t *testing.T) {
+	cs := &changeset{bump: minor, summary: "Added feature", author: "@me"}
+
+	tmpl := `{{range .Groups}}{{range .Entries}}{{template "entry" .}}{{end}}{{end}}{{define "entry"}}+ {{.Summary}} by {{.Author}}
+{{end}}`
+	got, err := renderChangelogEntry(cs, changelogOptions{template: tmpl, showAuthors: true})
+	if err != nil {
+		t.Fatalf("renderChangelogEntry failed: %v", err)
+	}
+	if got != "+ Added feature by @me\n" {
+		t.Errorf("got %q, want %q", got, "+ Added feature by @me\n")
+	}
+}
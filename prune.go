@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pruneOptions holds the flags accepted by the prune command.
+type pruneOptions struct {
+	force bool // remove without asking for confirmation
+}
+
+// parsePruneFlags parses the arguments following "prune".
+func parsePruneFlags(args []string) (pruneOptions, error) {
+	var opts pruneOptions
+	fs := newFlagSet("prune")
+	fs.BoolVar(&opts.force, "force", false, "remove the files without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// prunable is a file in the changes directory that prune would remove.
+type prunable struct {
+	path   string
+	reason string
+}
+
+// findPrunable returns the .md files in the changes directory that are empty
+// or fail to parse. Valid changesets are never included, and neither are
+// files that only exceed the size limit, since they may well be valid.
+func findPrunable(changesDir string, opts parseOptions) ([]prunable, error) {
+	entries, err := os.ReadDir(changesDir)
+	if err != nil {
+		return nil, fmt.Errorf("read changes directory: %w", err)
+	}
+
+	var found []prunable
+	for _, entry := range entries {
+		if entry.IsDir() || !isChangesetFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(changesDir, entry.Name())
+		_, err := parseFile(path, opts)
+		var pathErr *fs.PathError
+		switch {
+		case errors.As(err, &pathErr):
+			return nil, err
+		case err == nil, errors.Is(err, errChangesetTooLarge):
+		case errors.Is(err, errChangesetEmpty):
+			found = append(found, prunable{path: path, reason: "empty"})
+		default:
+			found = append(found, prunable{path: path, reason: err.Error()})
+		}
+	}
+
+	return found, nil
+}
+
+// cmdPrune removes empty and unparseable files from the changes directory,
+// asking for confirmation unless opts.force is set.
+func cmdPrune(p paths, scanner *bufio.Scanner, opts pruneOptions) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	// Refuse to delete anything outside the project.
+	if err := ensureWithinRoot(p.root, p.changes); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	found, err := findPrunable(p.changes, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		fmt.Println("Nothing to prune.")
+		return nil
+	}
+
+	for _, f := range found {
+		fmt.Printf("  %s: %s\n", filepath.Base(f.path), f.reason)
+	}
+
+	if !opts.force {
+		fmt.Printf("Remove %d files? (y/n): ", len(found))
+		if !scanner.Scan() {
+			return fmt.Errorf("no input received")
+		}
+		if !strings.EqualFold(strings.TrimSpace(scanner.Text()), "y") {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	for _, f := range found {
+		if err := os.Remove(f.path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", filepath.Base(f.path), err)
+		}
+		fmt.Printf("Removed %s\n", filepath.Base(f.path))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdPruneForce(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"",
+		"  \n",
+		"no frontmatter",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdPrune(p, newScanner(""), pruneOptions{force: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdPrune failed: %v", err)
+	}
+	if !strings.Contains(output, "change-1.md: empty") {
+		t.Errorf("expected the empty file to be reported, got %q", output)
+	}
+	if !strings.Contains(output, "Removed change-3.md") {
+		t.Errorf("expected the unparseable file to be removed, got %q", output)
+	}
+
+	entries, _ := os.ReadDir(p.changes)
+	var left []string
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".md") {
+			left = append(left, e.Name())
+		}
+	}
+	if len(left) != 1 || left[0] != "change-0.md" {
+		t.Errorf("expected only the valid changeset to remain, got %v", left)
+	}
+}
+
+func TestFindPrunableKeepsOversized(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\n"+strings.Repeat("Long summary. ", 20),
+		"",
+	)
+
+	found, err := findPrunable(p.changes, parseOptions{maxSize: 64})
+	if err != nil {
+		t.Fatalf("findPrunable failed: %v", err)
+	}
+	if len(found) != 1 || filepath.Base(found[0].path) != "change-1.md" || found[0].reason != "empty" {
+		t.Errorf("expected only the empty file, got %+v", found)
+	}
+
+	if _, err := findPrunable(filepath.Join(p.changes, "missing"), parseOptions{}); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+
+	// A file that cannot be read is an error, not something to delete.
+	if err := os.Symlink(filepath.Join(p.root, "nowhere.md"), filepath.Join(p.changes, "dangling.md")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if _, err := findPrunable(p.changes, parseOptions{}); err == nil {
+		t.Error("expected error for an unreadable changeset")
+	}
+}
+
+func TestCmdPruneConfirm(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "")
+	path := filepath.Join(p.changes, "change-0.md")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdPrune(p, newScanner("n\n"), pruneOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdPrune failed: %v", err)
+	}
+	if !strings.Contains(output, "Aborted.") {
+		t.Errorf("expected abort message, got %q", output)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("file should be kept when removal is declined")
+	}
+
+	captureStdout(func() {
+		err = cmdPrune(p, newScanner("y\n"), pruneOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdPrune failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("file should be removed after confirming")
+	}
+}
+
+func TestCmdPruneNothing(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+
+	output := captureStdout(func() {
+		if err := cmdPrune(p, newScanner(""), pruneOptions{}); err != nil {
+			t.Fatalf("cmdPrune failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Nothing to prune.") {
+		t.Errorf("unexpected output %q", output)
+	}
+}
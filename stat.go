@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// statOptions holds the flags accepted by the stat command.
+type statOptions struct {
+	json   bool         // print the statistics as JSON
+	pretty optionalBool // indent --json output; defaults to whether stdout is a terminal
+}
+
+// parseStatFlags parses the arguments following "stat".
+func parseStatFlags(args []string) (statOptions, error) {
+	var opts statOptions
+	fs := newFlagSet("stat")
+	fs.BoolVar(&opts.json, "json", false, "print the statistics as JSON")
+	fs.Var(&opts.pretty, "pretty", "indent JSON output (default: when stdout is a terminal)")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// releaseStat describes a single release found in the changelog.
+type releaseStat struct {
+	Version string `json:"version"`
+	Date    string `json:"date,omitempty"` // empty when the header has no date
+	Bump    string `json:"bump,omitempty"` // empty when the version cannot be parsed
+	Entries int    `json:"entries"`
+}
+
+// changelogStats summarizes the release history of a changelog.
+type changelogStats struct {
+	Releases           []releaseStat `json:"releases"` // newest first
+	Major              int           `json:"major"`
+	Minor              int           `json:"minor"`
+	Patch              int           `json:"patch"`
+	AverageEntries     float64       `json:"averageEntries"`
+	AverageDaysBetween float64       `json:"averageDaysBetween"` // zero with fewer than two dated releases
+}
+
+// cmdStat prints statistics about the releases recorded in CHANGELOG.md and
+// its archive.
+func cmdStat(p paths, opts statOptions) error {
+	content, err := readChangelogHistory(filepath.Join(p.root, changelogFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read CHANGELOG.md: %w", err)
+	}
+
+	stats := computeChangelogStats(content)
+	if opts.json {
+		if err := writeJSON(os.Stdout, stats, prettyJSON(opts.pretty)); err != nil {
+			return fmt.Errorf("failed to write changelog stats: %w", err)
+		}
+		return nil
+	}
+	return printChangelogStats(os.Stdout, stats)
+}
+
+// computeChangelogStats reads every release section of a changelog. Each
+// release is classified by comparing its version with the one before it; the
+// oldest release is compared with v0.0.0.
+func computeChangelogStats(content string) changelogStats {
+	stats := changelogStats{Releases: []releaseStat{}}
+	sections := parseChangelogSections(content)
+
+	var entries, intervals int
+	var days float64
+	for i, s := range sections {
+		older := "v0.0.0"
+		if i+1 < len(sections) {
+			older = sections[i+1].version
+		}
+
+		r := releaseStat{
+			Version: s.version,
+			Date:    s.date,
+			Bump:    string(releaseBump(older, s.version)),
+			Entries: countEntries(content[s.start:s.end]),
+		}
+		switch bumpType(r.Bump) {
+		case major:
+			stats.Major++
+		case minor:
+			stats.Minor++
+		case patch:
+			stats.Patch++
+		}
+		entries += r.Entries
+		stats.Releases = append(stats.Releases, r)
+
+		if i+1 < len(sections) {
+			newerDate, err1 := time.Parse(releaseDateLayout, s.date)
+			olderDate, err2 := time.Parse(releaseDateLayout, sections[i+1].date)
+			if err1 == nil && err2 == nil {
+				days += newerDate.Sub(olderDate).Hours() / 24
+				intervals++
+			}
+		}
+	}
+
+	if len(sections) > 0 {
+		stats.AverageEntries = float64(entries) / float64(len(sections))
+	}
+	if intervals > 0 {
+		stats.AverageDaysBetween = days / float64(intervals)
+	}
+	return stats
+}
+
+// releaseBump returns the bump that leads from older to newer, or an empty
+// bump type when either version cannot be parsed.
+func releaseBump(older, newer string) bumpType {
+	o, err := semver.NewVersion(strings.TrimPrefix(older, "v"))
+	if err != nil {
+		return ""
+	}
+	n, err := semver.NewVersion(strings.TrimPrefix(newer, "v"))
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case n.Major() != o.Major():
+		return major
+	case n.Minor() != o.Minor():
+		return minor
+	default:
+		return patch
+	}
+}
+
+// countEntries counts the top-level list items in a changelog section.
+func countEntries(section string) int {
+	n := 0
+	for _, line := range strings.Split(section, "\n") {
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+			n++
+		}
+	}
+	return n
+}
+
+// printChangelogStats writes a table of releases followed by the totals.
+func printChangelogStats(w io.Writer, stats changelogStats) error {
+	if len(stats.Releases) == 0 {
+		_, err := fmt.Fprintln(w, "No releases in CHANGELOG.md.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tDATE\tBUMP\tENTRIES")
+	for _, r := range stats.Releases {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", r.Version, orDash(r.Date), orDash(r.Bump), r.Entries)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Releases: %d (major %d, minor %d, patch %d)\n", len(stats.Releases), stats.Major, stats.Minor, stats.Patch)
+	fmt.Fprintf(w, "Average entries per release: %.1f\n", stats.AverageEntries)
+	if stats.AverageDaysBetween > 0 {
+		fmt.Fprintf(w, "Average days between releases: %.1f\n", stats.AverageDaysBetween)
+	}
+	return nil
+}
+
+// orDash returns s, or "-" when it is empty, for table cells.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const statChangelog = `# Changelog
+
+## v2.0.0 - 2026-03-11
+
+### Major Changes
+
+- abc1234: Removed old API
+  - with a nested detail
+
+### Patch Changes
+
+- Fixed bug
+
+## v1.1.0 - 2026-03-01
+
+### Minor Changes
+
+- Added feature
+
+## v1.0.1 - 2026-02-01
+
+### Patch Changes
+
+- Fixed typo
+
+## v1.0.0
+
+- Initial release
+`
+
+func TestComputeChangelogStats(t *testing.T) {
+	stats := computeChangelogStats(statChangelog)
+
+	expected := []releaseStat{
+		{Version: "v2.0.0", Date: "2026-03-11", Bump: "major", Entries: 2},
+		{Version: "v1.1.0", Date: "2026-03-01", Bump: "minor", Entries: 1},
+		{Version: "v1.0.1", Date: "2026-02-01", Bump: "patch", Entries: 1},
+		{Version: "v1.0.0", Bump: "major", Entries: 1},
+	}
+	if len(stats.Releases) != len(expected) {
+		t.Fatalf("expected %d releases, got %+v", len(expected), stats.Releases)
+	}
+	for i, r := range stats.Releases {
+		if r != expected[i] {
+			t.Errorf("release %d = %+v, expected %+v", i, r, expected[i])
+		}
+	}
+
+	if stats.Major != 2 || stats.Minor != 1 || stats.Patch != 1 {
+		t.Errorf("unexpected counts major=%d minor=%d patch=%d", stats.Major, stats.Minor, stats.Patch)
+	}
+	if stats.AverageEntries != 1.25 {
+		t.Errorf("expected 1.25 entries per release, got %v", stats.AverageEntries)
+	}
+	// 10 and 28 days between the dated releases.
+	if stats.AverageDaysBetween != 19 {
+		t.Errorf("expected 19 days between releases, got %v", stats.AverageDaysBetween)
+	}
+}
+
+func TestPrintChangelogStats(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printChangelogStats(&buf, computeChangelogStats(statChangelog)); err != nil {
+		t.Fatalf("printChangelogStats failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"VERSION  DATE        BUMP   ENTRIES\n",
+		"v1.0.0   -           major  1\n",
+		"Releases: 4 (major 2, minor 1, patch 1)\n",
+		"Average days between releases: 19.0\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	printChangelogStats(&buf, computeChangelogStats(""))
+	if buf.String() != "No releases in CHANGELOG.md.\n" {
+		t.Errorf("unexpected output for empty changelog: %q", buf.String())
+	}
+}
+
+func TestCmdStatJSON(t *testing.T) {
+	p := setupProject(t, "v2.0.0")
+	os.WriteFile(filepath.Join(p.root, "CHANGELOG.md"), []byte(statChangelog), 0644)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdStat(p, statOptions{json: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdStat failed: %v", err)
+	}
+
+	var stats changelogStats
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, output)
+	}
+	if len(stats.Releases) != 4 || stats.Major != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// statusOptions holds the flags accepted by the status command.
+type statusOptions struct {
+	countByBump bool         // print only the number of pending changesets per bump type
+	json        bool         // print the counts as JSON
+	lenientBump bool         // treat unknown bump types as patch
+	porcelain   bool         // print the stable, tab-separated format for scripts
+	width       int          // truncate summaries to this many columns; zero detects the terminal width
+	long        bool         // print full summaries without truncation
+	pretty      optionalBool // indent --json output; defaults to whether stdout is a terminal
+	watch       bool         // redraw the status whenever the changesets or config change
+}
+
+// parseStatusFlags parses the arguments following "status".
+func parseStatusFlags(args []string) (statusOptions, error) {
+	var opts statusOptions
+	fs := newFlagSet("status")
+	fs.BoolVar(&opts.countByBump, "count-by-bump", false, "print the number of pending changesets per bump type")
+	fs.BoolVar(&opts.json, "json", false, "print the counts as JSON (with --count-by-bump)")
+	fs.BoolVar(&opts.lenientBump, "lenient-bump", false, "treat unknown bump types as patch instead of failing")
+	fs.BoolVar(&opts.porcelain, "porcelain", false, "print a stable, tab-separated format for scripts")
+	fs.IntVar(&opts.width, "width", 0, "truncate summaries to `columns` (default: terminal width)")
+	fs.BoolVar(&opts.long, "long", false, "print full summaries without truncation")
+	fs.Var(&opts.pretty, "pretty", "indent JSON output (default: when stdout is a terminal)")
+	fs.BoolVar(&opts.watch, "watch", false, "redraw the status whenever a changeset is added, edited, or removed")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.width < 0 {
+		return opts, fmt.Errorf("invalid width %d, expected a positive number of columns", opts.width)
+	}
+
+	if opts.json && !opts.countByBump {
+		return opts, fmt.Errorf("--json requires --count-by-bump")
+	}
+	if opts.porcelain && opts.countByBump {
+		return opts, fmt.Errorf("--porcelain cannot be combined with --count-by-bump")
+	}
+	if opts.watch && (opts.porcelain || opts.json) {
+		return opts, fmt.Errorf("--watch cannot be combined with --porcelain or --json")
+	}
+	return opts, nil
+}
+
+// bumpCounts is the number of pending changesets per bump type.
+type bumpCounts struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+	None  int `json:"none"`
+}
+
+// countByBump tallies changesets by their bump type.
+func countByBump(changes []*changeset) bumpCounts {
+	var counts bumpCounts
+	for _, cs := range changes {
+		switch cs.bump {
+		case major:
+			counts.Major++
+		case minor:
+			counts.Minor++
+		case patch:
+			counts.Patch++
+		case none:
+			counts.None++
+		}
+	}
+	return counts
+}
+
+// printBumpCounts writes counts as "major=1 minor=3 patch=7 none=0", or as JSON.
+func printBumpCounts(w io.Writer, counts bumpCounts, asJSON, pretty bool) error {
+	if !asJSON {
+		_, err := fmt.Fprintf(w, "major=%d minor=%d patch=%d none=%d\n", counts.Major, counts.Minor, counts.Patch, counts.None)
+		return err
+	}
+
+	if err := writeJSON(w, counts, pretty); err != nil {
+		return fmt.Errorf("failed to write bump counts: %w", err)
+	}
+	return nil
+}
+
+// printPorcelainStatus writes the stable status format. It is a compatibility
+// contract for scripts and must not change: a first line with the current and
+// next version, then one line per changeset with its bump, slug, and the first
+// line of its summary, all separated by tabs.
+func printPorcelainStatus(w io.Writer, current, next string, changes []*changeset) error {
+	if _, err := fmt.Fprintf(w, "%s\t%s\n", current, next); err != nil {
+		return err
+	}
+	for _, cs := range changes {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", cs.bump, cs.slug, firstLine(cs.summary)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staleChangeset is a pending changeset older than the configured threshold.
+type staleChangeset struct {
+	cs   *changeset
+	days int
+}
+
+// cmdStatus prints the current and next version along with pending changesets.
+// With opts.watch it keeps redrawing until interrupted.
+func cmdStatus(p paths, opts statusOptions) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	if opts.watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		opts.watch = false
+		return watchStatus(ctx, p, opts, watchInterval)
+	}
+
+	nextVer, changes, cfg, err := calculateNextVersion(p, opts.lenientBump, false, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	if opts.countByBump {
+		return printBumpCounts(os.Stdout, countByBump(changes), opts.json, prettyJSON(opts.pretty))
+	}
+
+	sortChangesets(changes, sortBySlug)
+	if opts.porcelain {
+		return printPorcelainStatus(os.Stdout, cfg.Version, nextVer, changes)
+	}
+
+	fmt.Printf("Current version: %s\n", cfg.Version)
+	fmt.Printf("Next version:    %s\n", nextVer)
+	fmt.Println()
+
+	if len(cfg.Packages) > 0 {
+		if err := printPackageVersions(os.Stdout, cfg, changes); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No pending changesets.")
+		return nil
+	}
+
+	fmt.Printf("Pending changesets (%d):\n", len(changes))
+	if err := printChangesets(os.Stdout, changes, summaryWidth(opts.width, opts.long)); err != nil {
+		return err
+	}
+
+	printStaleWarnings(os.Stderr, findStaleChangesets(changes, cfg.StaleDays, time.Now()))
+	return nil
+}
+
+// printPackageVersions writes the current and next version of every tracked
+// workspace package, sorted by name.
+func printPackageVersions(w io.Writer, cfg *config, changes []*changeset) error {
+	next, err := nextPackageVersions(cfg, changes)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(next))
+	for name := range next {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Packages:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\t-> %s\n", name, cfg.packageVersion(name), next[name])
+	}
+	return tw.Flush()
+}
+
+// findStaleChangesets returns the changesets added at least days ago.
+// A threshold of zero or less disables the check.
+func findStaleChangesets(changes []*changeset, days int, now time.Time) []staleChangeset {
+	if days <= 0 {
+		return nil
+	}
+
+	var stale []staleChangeset
+	for _, cs := range changes {
+		added := changesetTime(cs)
+		if added.IsZero() {
+			continue
+		}
+		age := int(now.Sub(added).Hours() / 24)
+		if age >= days {
+			stale = append(stale, staleChangeset{cs: cs, days: age})
+		}
+	}
+
+	return stale
+}
+
+// printStaleWarnings writes one warning line per stale changeset.
+func printStaleWarnings(w io.Writer, stale []staleChangeset) {
+	for _, s := range stale {
+		fmt.Fprintf(w, "warning: changeset %s is %d days old, consider releasing or removing it\n", s.cs.slug, s.days)
+	}
+}
+
+// watchInterval is how often status --watch polls for changes. Polling keeps
+// the tool free of a file notification dependency, and the changes directory
+// is small enough that a stat per file costs nothing.
+const watchInterval = 500 * time.Millisecond
+
+// clearScreen moves the cursor to the top left and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// watchStatus clears the screen and prints the status each time the
+// changesets or config change, until ctx is cancelled. Errors such as a
+// half-written changeset are shown in place of the status rather than ending
+// the watch.
+func watchStatus(ctx context.Context, p paths, opts statusOptions, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		if snapshot := watchSnapshot(p); snapshot != last {
+			last = snapshot
+			fmt.Print(clearScreen)
+			if err := cmdStatus(p, opts); err != nil {
+				fmt.Printf("error: %s\n", err)
+			}
+			fmt.Printf("\nWatching %s for changes, press Ctrl-C to stop.\n", filepath.Base(p.changes))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchSnapshot returns a fingerprint of config.json and the changeset files,
+// which differs whenever one of them is added, removed, or modified.
+func watchSnapshot(p paths) string {
+	var sb strings.Builder
+	if info, err := os.Stat(p.config); err == nil {
+		fmt.Fprintf(&sb, "%s %d %d\n", info.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+
+	entries, err := os.ReadDir(p.changes)
+	if err != nil {
+		return sb.String()
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isChangesetFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s %d %d\n", info.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String()
+}
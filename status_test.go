@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCmdStatus(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"---\ntest: minor\n---\n\nFeature",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdStatus(p, statusOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdStatus failed: %v", err)
+	}
+	if !strings.Contains(output, "Current version: v1.0.0") {
+		t.Errorf("expected current version, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Next version:    v1.1.0") {
+		t.Errorf("expected next version, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Pending changesets (2)") {
+		t.Errorf("expected pending count, got:\n%s", output)
+	}
+}
+
+func TestCmdStatusCountByBump(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"---\ntest: minor\n---\n\nFeature",
+		"---\ntest: patch\n---\n\nAnother fix",
+		"---\ntest: none\n---\n\nDocs note",
+	)
+
+	output := captureStdout(func() {
+		if err := cmdStatus(p, statusOptions{countByBump: true}); err != nil {
+			t.Fatalf("cmdStatus failed: %v", err)
+		}
+	})
+	if output != "major=0 minor=1 patch=2 none=1\n" {
+		t.Errorf("unexpected counts %q", output)
+	}
+
+	output = captureStdout(func() {
+		if err := cmdStatus(p, statusOptions{countByBump: true, json: true}); err != nil {
+			t.Fatalf("cmdStatus failed: %v", err)
+		}
+	})
+	if output != `{"major":0,"minor":1,"patch":2,"none":1}`+"\n" {
+		t.Errorf("unexpected JSON counts %q", output)
+	}
+}
+
+func TestCmdStatusPorcelain(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix\n\nDetails",
+		"---\ntest: minor\n---\n\nFeature",
+	)
+
+	output := captureStdout(func() {
+		if err := cmdStatus(p, statusOptions{porcelain: true}); err != nil {
+			t.Fatalf("cmdStatus failed: %v", err)
+		}
+	})
+
+	expected := "v1.0.0\tv1.1.0\npatch\tchange-0\tFix\nminor\tchange-1\tFeature\n"
+	if output != expected {
+		t.Errorf("unexpected porcelain output.\nExpected:\n%q\nGot:\n%q", expected, output)
+	}
+}
+
+func TestParseStatusFlags(t *testing.T) {
+	opts, err := parseStatusFlags([]string{"--count-by-bump", "--json"})
+	if err != nil {
+		t.Fatalf("parseStatusFlags failed: %v", err)
+	}
+	if !opts.countByBump || !opts.json {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if _, err := parseStatusFlags([]string{"--porcelain", "--count-by-bump"}); err == nil {
+		t.Error("expected error for --porcelain with --count-by-bump")
+	}
+	if _, err := parseStatusFlags([]string{"--json"}); err == nil {
+		t.Error("expected error for --json without --count-by-bump")
+	}
+	if _, err := parseStatusFlags([]string{"--watch", "--porcelain"}); err == nil {
+		t.Error("expected error for --watch with --porcelain")
+	}
+	if _, err := parseStatusFlags([]string{"--bogus"}); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var err error
+	output := captureStdout(func() {
+		done := make(chan struct{})
+		go func() {
+			err = watchStatus(ctx, p, statusOptions{}, 10*time.Millisecond)
+			close(done)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(filepath.Join(p.changes, "new-one.md"), []byte("---\ntest: minor\n---\n\nFeature"), 0644)
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		<-done
+	})
+	if err != nil {
+		t.Fatalf("watchStatus failed: %v", err)
+	}
+
+	if n := strings.Count(output, clearScreen); n != 2 {
+		t.Errorf("expected 2 redraws, got %d:\n%s", n, output)
+	}
+	if !strings.Contains(output, "Pending changesets (1):") || !strings.Contains(output, "Pending changesets (2):") {
+		t.Errorf("expected the status before and after the new changeset, got:\n%s", output)
+	}
+}
+
+func TestPrintPackageVersions(t *testing.T) {
+	cfg := &config{Packages: map[string]string{"web": "v0.3.1", "api": "v1.2.0"}}
+	changes := []*changeset{{releases: []release{{name: "api", bump: minor}}}}
+
+	var buf bytes.Buffer
+	if err := printPackageVersions(&buf, cfg, changes); err != nil {
+		t.Fatalf("printPackageVersions failed: %v", err)
+	}
+
+	output := buf.String()
+	api := strings.Index(output, "api  v1.2.0  -> v1.3.0")
+	web := strings.Index(output, "web  v0.3.1  -> v0.3.1")
+	if api < 0 || web < 0 || api > web {
+		t.Errorf("unexpected package listing:\n%s", output)
+	}
+}
+
+func TestCmdStatusEmpty(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdStatus(p, statusOptions{})
+	})
+	if err != nil {
+		t.Fatalf("cmdStatus failed: %v", err)
+	}
+	if !strings.Contains(output, "No pending changesets") {
+		t.Errorf("expected empty message, got:\n%s", output)
+	}
+}
+
+func TestCmdStatusNoDir(t *testing.T) {
+	p := newPaths(t.TempDir())
+	if err := cmdStatus(p, statusOptions{}); err == nil {
+		t.Fatal("expected error when .changesets doesn't exist")
+	}
+}
+
+func TestCmdStatusCalculateError(t *testing.T) {
+	p := newPaths(t.TempDir())
+	os.MkdirAll(p.changes, 0755)
+
+	if err := cmdStatus(p, statusOptions{}); err == nil {
+		t.Fatal("expected error when config is missing")
+	}
+}
+
+func TestFindStaleChangesets(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	oldPath := filepath.Join(dir, "old.md")
+	newPath := filepath.Join(dir, "new.md")
+	os.WriteFile(oldPath, []byte("x"), 0644)
+	os.WriteFile(newPath, []byte("x"), 0644)
+	old := now.Add(-40 * 24 * time.Hour)
+	os.Chtimes(oldPath, old, old)
+
+	changes := []*changeset{
+		{filepath: oldPath, slug: "old"},
+		{filepath: newPath, slug: "new"},
+	}
+
+	stale := findStaleChangesets(changes, 30, now)
+	if len(stale) != 1 {
+		t.Fatalf("expected 1 stale changeset, got %d", len(stale))
+	}
+	if stale[0].cs.slug != "old" || stale[0].days != 40 {
+		t.Errorf("expected old at 40 days, got %s at %d days", stale[0].cs.slug, stale[0].days)
+	}
+}
+
+func TestFindStaleChangesetsDisabled(t *testing.T) {
+	changes := []*changeset{{filepath: "/nonexistent/file.md", slug: "x"}}
+	if stale := findStaleChangesets(changes, 0, time.Now()); stale != nil {
+		t.Errorf("expected no stale changesets when disabled, got %d", len(stale))
+	}
+}
+
+func TestFindStaleChangesetsUnknownAge(t *testing.T) {
+	changes := []*changeset{{filepath: "/nonexistent/file.md", slug: "x"}}
+	if stale := findStaleChangesets(changes, 1, time.Now()); len(stale) != 0 {
+		t.Errorf("expected changesets without a known age to be skipped, got %d", len(stale))
+	}
+}
+
+func TestPrintStaleWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	printStaleWarnings(&buf, []staleChangeset{{cs: &changeset{slug: "brave-orange-fox"}, days: 45}})
+
+	if !strings.Contains(buf.String(), "brave-orange-fox is 45 days old") {
+		t.Errorf("unexpected warning: %q", buf.String())
+	}
+}
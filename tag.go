@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+)
+
+// tagOptions holds the flags accepted by the tag command.
+type tagOptions struct {
+	preview bool // print the commit and tag name without creating anything
+}
+
+// parseTagFlags parses the arguments following "tag".
+func parseTagFlags(args []string) (tagOptions, error) {
+	var opts tagOptions
+	fs := newFlagSet("tag")
+	fs.BoolVar(&opts.preview, "preview", false, "print the commit and tag name the release would be tagged with")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// cmdTag shows which commit the released version would be tagged on. Only
+// --preview is supported: the CLI never creates or pushes tags itself, so this
+// is a read-only check to run before git tag.
+func cmdTag(p paths, opts tagOptions) error {
+	if !opts.preview {
+		return fmt.Errorf("tag only supports --preview; create the tag with git tag once the preview looks right")
+	}
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	sha, subject, err := gitHead(p.root)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Would tag %s (%s) as %s\n", sha, subject, cfg.Version)
+	return nil
+}
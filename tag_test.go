@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCmdTagPreview(t *testing.T) {
+	p := setupProject(t, "v1.2.0")
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "nesymno@gmail.com"},
+		{"config", "user.name", "nesymno"},
+		{"add", "."},
+		{"commit", "-m", "Release v1.2.0"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", p.root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	head, _ := exec.Command("git", "-C", p.root, "rev-parse", "HEAD").Output()
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdTag(p, tagOptions{preview: true})
+	})
+	if err != nil {
+		t.Fatalf("cmdTag failed: %v", err)
+	}
+	want := fmt.Sprintf("Would tag %s (Release v1.2.0) as v1.2.0\n", strings.TrimSpace(string(head)))
+	if output != want {
+		t.Errorf("expected %q, got %q", want, output)
+	}
+
+	if err := cmdTag(p, tagOptions{}); err == nil {
+		t.Error("expected tag without --preview to fail")
+	}
+}
+
+func TestCmdTagPreviewNotARepo(t *testing.T) {
+	p := setupProject(t, "v1.2.0")
+	if err := cmdTag(p, tagOptions{preview: true}); err == nil {
+		t.Error("expected an error outside a git repository")
+	}
+}
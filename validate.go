@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// validateOptions holds the flags accepted by the validate command.
+type validateOptions struct {
+	verboseParse bool         // also print the parsed result of each valid changeset
+	strict       bool         // treat filenames that do not match the slug pattern as errors
+	json         bool         // print a report of every changeset as JSON
+	pretty       optionalBool // indent --json output; defaults to whether stdout is a terminal
+}
+
+// parseValidateFlags parses the arguments following "validate".
+func parseValidateFlags(args []string) (validateOptions, error) {
+	var opts validateOptions
+	fs := newFlagSet("validate")
+	fs.BoolVar(&opts.verboseParse, "verbose-parse", false, "print the parsed result of every valid changeset")
+	fs.BoolVar(&opts.strict, "strict", false, "fail when a changeset filename does not match the slug pattern")
+	fs.BoolVar(&opts.json, "json", false, "print a report of every changeset as JSON")
+	fs.Var(&opts.pretty, "pretty", "indent JSON output (default: when stdout is a terminal)")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.json && opts.verboseParse {
+		return opts, fmt.Errorf("--json cannot be combined with --verbose-parse")
+	}
+	return opts, nil
+}
+
+// validateReport is the output of validate --json.
+type validateReport struct {
+	Valid      bool             `json:"valid"`
+	Changesets []validateResult `json:"changesets"`
+}
+
+// validateResult is the outcome of checking one changeset file.
+type validateResult struct {
+	File     string   `json:"file"`
+	OK       bool     `json:"ok"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings"`
+}
+
+// cmdValidate parses every pending changeset and reports each invalid one,
+// rather than stopping at the first error like the other commands.
+func cmdValidate(p paths, opts validateOptions) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	summaryRe, err := cfg.summaryPattern()
+	if err != nil {
+		return err
+	}
+
+	parsed, errs, err := collectChangesets(p.changes, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+
+	var changes []*changeset
+	for _, cs := range parsed {
+		if err := checkSummary(summaryRe, cs.summary); err != nil {
+			errs = append(errs, &fileError{file: filepath.Base(cs.filepath), err: err})
+			continue
+		}
+		changes = append(changes, cs)
+	}
+
+	conflicts := findBumpConflicts(changes)
+	dropped := findDroppedSummaries(changes, cfg.SummaryRender)
+	badNames, err := findBadFilenames(p.changes, cfg.withDefaults().SlugSeparator)
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		report := newValidateReport(changes, errs, conflicts, dropped, badNames, opts.strict)
+		if err := writeJSON(os.Stdout, report, prettyJSON(opts.pretty)); err != nil {
+			return err
+		}
+	} else {
+		if opts.verboseParse && len(changes) > 0 {
+			sortChangesets(changes, sortBySlug)
+			if err := printParsedChangesets(os.Stdout, changes); err != nil {
+				return err
+			}
+		}
+
+		printBumpConflicts(os.Stderr, conflicts)
+		for _, e := range dropped {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+
+		level := "warning"
+		if opts.strict {
+			level = "invalid"
+		}
+		for _, e := range badNames {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", level, e)
+		}
+
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "invalid: %s\n", e)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d changesets are invalid", len(errs), len(errs)+len(changes))
+	}
+	if opts.strict && len(badNames) > 0 {
+		return fmt.Errorf("%d changeset filenames do not match the slug pattern", len(badNames))
+	}
+
+	if !opts.json {
+		fmt.Printf("All %d changesets are valid.\n", len(changes))
+	}
+	return nil
+}
+
+// newValidateReport gathers the results of validate into one entry per
+// changeset file, sorted by name. Bump conflicts and dropped summary lines are
+// always warnings, and filename problems are errors only when strict is set.
+func newValidateReport(changes []*changeset, errs []error, conflicts []bumpConflict, dropped, badNames []error, strict bool) validateReport {
+	results := make(map[string]*validateResult)
+	result := func(file string) *validateResult {
+		r, ok := results[file]
+		if !ok {
+			r = &validateResult{File: file, OK: true, Warnings: []string{}}
+			results[file] = r
+		}
+		return r
+	}
+
+	for _, cs := range changes {
+		result(filepath.Base(cs.filepath))
+	}
+	for _, e := range errs {
+		file, msg := splitFileError(e)
+		r := result(file)
+		r.OK, r.Error = false, msg
+	}
+	for _, c := range conflicts {
+		for _, name := range c.names {
+			r := result(name)
+			r.Warnings = append(r.Warnings, c.String())
+		}
+	}
+	for _, e := range dropped {
+		file, msg := splitFileError(e)
+		r := result(file)
+		r.Warnings = append(r.Warnings, msg)
+	}
+	for _, e := range badNames {
+		file, msg := splitFileError(e)
+		r := result(file)
+		if strict {
+			r.OK = false
+		}
+		if strict && r.Error == "" {
+			r.Error = msg
+		} else {
+			r.Warnings = append(r.Warnings, msg)
+		}
+	}
+
+	report := validateReport{Valid: true, Changesets: []validateResult{}}
+	for _, r := range results {
+		report.Changesets = append(report.Changesets, *r)
+		report.Valid = report.Valid && r.OK
+	}
+	sort.Slice(report.Changesets, func(i, j int) bool {
+		return report.Changesets[i].File < report.Changesets[j].File
+	})
+	return report
+}
+
+// splitFileError returns the file an error is about and the error without
+// the file name, or an empty file when err is not a *fileError.
+func splitFileError(err error) (file, msg string) {
+	var fe *fileError
+	if errors.As(err, &fe) {
+		return fe.file, fe.err.Error()
+	}
+	return "", err.Error()
+}
+
+// findBadFilenames checks the name of every changeset file in dir against
+// the slug pattern add uses, returning one error per offending file.
+func findBadFilenames(dir, sep string) ([]error, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read changes directory: %w", err)
+	}
+
+	var bad []error
+	for _, entry := range entries {
+		if entry.IsDir() || !isChangesetFile(entry.Name()) {
+			continue
+		}
+		if err := checkSlugFilename(entry.Name(), sep); err != nil {
+			bad = append(bad, &fileError{file: entry.Name(), err: fmt.Errorf("filename %w", err)})
+		}
+	}
+	return bad, nil
+}
+
+// printParsedChangesets writes a table of what was parsed from each changeset.
+func printParsedChangesets(w io.Writer, changes []*changeset) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tPACKAGE\tBUMP\tSUMMARY")
+	for _, cs := range changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", filepath.Base(cs.filepath), cs.repoName, cs.bump, firstLine(cs.summary))
+	}
+	return tw.Flush()
+}
+
+// bumpConflict is a package given different bumps by changesets that appear
+// to describe the same change.
+type bumpConflict struct {
+	pkg     string
+	summary string
+	files   []string // "name.md (bump)", in the order the changesets were read
+	names   []string // the same files without their bumps
+}
+
+func (c bumpConflict) String() string {
+	return fmt.Sprintf("conflicting bumps for %s in changesets with the same summary %q: %s", c.pkg, c.summary, strings.Join(c.files, ", "))
+}
+
+// findBumpConflicts looks for changesets with the same summary, compared
+// case-insensitively with whitespace collapsed, that bump a package
+// differently. Such pairs are usually a copied changeset whose bump was not
+// updated, so they are reported as warnings rather than errors.
+func findBumpConflicts(changes []*changeset) []bumpConflict {
+	type key struct{ pkg, summary string }
+	var order []key
+	files := make(map[key][]string)
+	names := make(map[key][]string)
+	firstSummary := make(map[key]string)
+	bumps := make(map[key]map[bumpType]bool)
+
+	for _, cs := range changes {
+		summary := strings.ToLower(strings.Join(strings.Fields(cs.summary), " "))
+		if summary == "" {
+			continue
+		}
+		for _, r := range cs.releases {
+			k := key{r.name, summary}
+			if bumps[k] == nil {
+				order = append(order, k)
+				firstSummary[k] = firstLine(cs.summary)
+				bumps[k] = make(map[bumpType]bool)
+			}
+			files[k] = append(files[k], fmt.Sprintf("%s (%s)", filepath.Base(cs.filepath), r.bump))
+			names[k] = append(names[k], filepath.Base(cs.filepath))
+			bumps[k][r.bump] = true
+		}
+	}
+
+	var conflicts []bumpConflict
+	for _, k := range order {
+		if len(bumps[k]) > 1 {
+			conflicts = append(conflicts, bumpConflict{pkg: k.pkg, summary: firstSummary[k], files: files[k], names: names[k]})
+		}
+	}
+	return conflicts
+}
+
+// printBumpConflicts writes a warning for each conflict.
+func printBumpConflicts(w io.Writer, conflicts []bumpConflict) {
+	for _, c := range conflicts {
+		fmt.Fprintf(w, "warning: %s\n", c)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdValidate(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"---\ntest: minor\n---\n\nFeature",
+	)
+
+	output := captureStdout(func() {
+		if err := cmdValidate(p, validateOptions{}); err != nil {
+			t.Fatalf("cmdValidate failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "All 2 changesets are valid.") {
+		t.Errorf("unexpected output %q", output)
+	}
+}
+
+func TestCmdValidateReportsAllErrors(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"no frontmatter",
+		"---\ntest: patch\n---\n\n",
+	)
+
+	err := cmdValidate(p, validateOptions{})
+	if err == nil {
+		t.Fatal("expected error for invalid changesets")
+	}
+	if !strings.Contains(err.Error(), "2 of 3 changesets are invalid") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCmdValidateSummaryMustMatch(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix crash (#12)",
+		"---\ntest: patch\n---\n\nFix typo",
+	)
+	saveConfig(p.config, &config{Version: "v1.0.0", SummaryMustMatch: `#\d+`})
+
+	err := cmdValidate(p, validateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 changesets are invalid") {
+		t.Fatalf("expected the summary without an issue to be invalid, got %v", err)
+	}
+
+	saveConfig(p.config, &config{Version: "v1.0.0", SummaryMustMatch: `(`})
+	if err := cmdValidate(p, validateOptions{}); err == nil || !strings.Contains(err.Error(), "invalid summaryMustMatch") {
+		t.Errorf("expected error for an invalid pattern, got %v", err)
+	}
+}
+
+func TestCmdValidateNoDir(t *testing.T) {
+	if err := cmdValidate(newPaths(t.TempDir()), validateOptions{}); err == nil {
+		t.Fatal("expected error without .changesets")
+	}
+}
+
+func TestCmdValidateVerboseParse(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: minor\n---\n\nAdded feature\n\nMore detail",
+		"no frontmatter",
+	)
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdValidate(p, validateOptions{verboseParse: true})
+	})
+	if err == nil {
+		t.Fatal("expected error for the invalid changeset")
+	}
+	if !strings.Contains(output, "FILE") || !strings.Contains(output, "change-0.md  test     minor  Added feature") {
+		t.Errorf("expected parsed table for the valid changeset, got:\n%s", output)
+	}
+	if strings.Contains(output, "change-1.md") {
+		t.Errorf("invalid changesets belong in the error report, got:\n%s", output)
+	}
+}
+
+func TestCmdValidateFilenames(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nFix")
+	if err := os.WriteFile(filepath.Join(p.changes, "Fix Login.md"), []byte("---\ntest: patch\n---\n\nFix login"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(func() {
+		if err := cmdValidate(p, validateOptions{}); err != nil {
+			t.Errorf("a bad filename should only warn by default, got %v", err)
+		}
+	})
+
+	err := cmdValidate(p, validateOptions{strict: true})
+	if err == nil || !strings.Contains(err.Error(), "1 changeset filenames do not match") {
+		t.Errorf("expected --strict to fail on the bad filename, got %v", err)
+	}
+}
+
+func TestCmdValidateJSON(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\ntest: patch\n---\n\nFix",
+		"no frontmatter",
+		"---\ntest: minor\n---\n\nfix",
+	)
+	if err := os.WriteFile(filepath.Join(p.changes, "Fix Login.md"), []byte("---\ntest: patch\n---\n\nFix login"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdValidate(p, validateOptions{json: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 changesets are invalid") {
+		t.Errorf("expected --json to still fail on the invalid changeset, got %v", err)
+	}
+
+	var report validateReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if report.Valid || len(report.Changesets) != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	byFile := make(map[string]validateResult)
+	for _, r := range report.Changesets {
+		byFile[r.File] = r
+	}
+	if r := byFile["change-0.md"]; !r.OK || len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "conflicting bumps") {
+		t.Errorf("expected change-0.md to be valid with a conflict warning, got %+v", r)
+	}
+	if r := byFile["change-1.md"]; r.OK || !strings.Contains(r.Error, "frontmatter") {
+		t.Errorf("expected change-1.md to be invalid, got %+v", r)
+	}
+	if r := byFile["Fix Login.md"]; !r.OK || len(r.Warnings) != 1 || !strings.HasPrefix(r.Warnings[0], "filename") {
+		t.Errorf("expected a filename warning for Fix Login.md, got %+v", r)
+	}
+}
+
+func TestCmdValidateDroppedSummary(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nReworked the parser\n\nIt is faster.")
+
+	var err error
+	output := captureStdout(func() {
+		err = cmdValidate(p, validateOptions{json: true})
+	})
+	if err != nil {
+		t.Fatalf("expected dropped summary lines to be a warning, got %v", err)
+	}
+
+	var report validateReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if len(report.Changesets) != 1 || len(report.Changesets[0].Warnings) != 1 || !strings.Contains(report.Changesets[0].Warnings[0], "left out of the changelog") {
+		t.Errorf("expected a dropped summary warning, got %+v", report)
+	}
+}
+
+func TestNewValidateReportStrict(t *testing.T) {
+	badNames := []error{&fileError{file: "Fix Login.md", err: errors.New("filename contains whitespace")}}
+
+	report := newValidateReport(nil, nil, nil, nil, badNames, true)
+	if report.Valid || len(report.Changesets) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if r := report.Changesets[0]; r.OK || r.Error != "filename contains whitespace" || len(r.Warnings) != 0 {
+		t.Errorf("expected the filename to be an error with --strict, got %+v", r)
+	}
+
+	if report := newValidateReport(nil, nil, nil, nil, nil, false); !report.Valid || report.Changesets == nil {
+		t.Errorf("expected an empty valid report, got %+v", report)
+	}
+}
+
+func TestParseValidateFlags(t *testing.T) {
+	opts, err := parseValidateFlags([]string{"--verbose-parse", "--strict"})
+	if err != nil {
+		t.Fatalf("parseValidateFlags failed: %v", err)
+	}
+	if !opts.verboseParse || !opts.strict {
+		t.Errorf("expected verbose-parse and strict to be set, got %+v", opts)
+	}
+	if _, err := parseValidateFlags([]string{"--bogus"}); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+	if _, err := parseValidateFlags([]string{"--json", "--verbose-parse"}); err == nil {
+		t.Error("expected --json with --verbose-parse to be rejected")
+	}
+}
+
+func TestFindBumpConflicts(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: major\n---\n\nRemove the legacy endpoint",
+		"---\napi: patch\nweb: patch\n---\n\nremove the  legacy endpoint",
+		"---\nweb: minor\n---\n\nRemove the legacy endpoint",
+		"---\napi: minor\n---\n\nUnrelated change",
+	)
+	changes, errs, err := collectChangesets(p.changes, parseOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("collectChangesets failed: %v %v", err, errs)
+	}
+
+	conflicts := findBumpConflicts(changes)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	if c := conflicts[0]; c.pkg != "api" || strings.Join(c.files, ", ") != "change-0.md (major), change-1.md (patch)" {
+		t.Errorf("unexpected api conflict %+v", c)
+	}
+	if c := conflicts[1]; c.pkg != "web" || strings.Join(c.files, ", ") != "change-1.md (patch), change-2.md (minor)" {
+		t.Errorf("unexpected web conflict %+v", c)
+	}
+
+	var buf strings.Builder
+	printBumpConflicts(&buf, conflicts[:1])
+	if want := "warning: conflicting bumps for api in changesets with the same summary \"Remove the legacy endpoint\": change-0.md (major), change-1.md (patch)\n"; buf.String() != want {
+		t.Errorf("unexpected warning %q", buf.String())
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// cmdVerifyChangelog checks that the topmost release in CHANGELOG.md matches
+// the version in config.json, so CI can catch a version that was bumped
+// without a release or a changelog that was edited by hand.
+func cmdVerifyChangelog(p paths) error {
+	if err := ensureChangesetsExist(p); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	latest, err := latestChangelogVersion(filepath.Join(p.root, changelogFile))
+	if err != nil {
+		return err
+	}
+
+	if err := compareChangelogVersion(latest, cfg.withDefaults().Version); err != nil {
+		return err
+	}
+
+	fmt.Printf("CHANGELOG.md matches config.json (%s)\n", cfg.withDefaults().Version)
+	return nil
+}
+
+// latestChangelogVersion returns the version of the topmost release section in
+// the changelog at path, or an empty string when there is none yet.
+func latestChangelogVersion(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read CHANGELOG.md: %w", err)
+	}
+
+	sections := parseChangelogSections(string(data))
+	if len(sections) == 0 {
+		return "", nil
+	}
+	return sections[0].version, nil
+}
+
+// compareChangelogVersion reports a mismatch between the latest changelog
+// version and the configured one. A project that has never been released may
+// have no changelog sections at all.
+func compareChangelogVersion(changelogVer, configVer string) error {
+	if changelogVer == "" {
+		if configVer == "v0.0.0" {
+			return nil
+		}
+		return fmt.Errorf("CHANGELOG.md has no release sections, but config.json is at %s", configVer)
+	}
+	if changelogVer != configVer {
+		return fmt.Errorf("CHANGELOG.md and config.json disagree:\n  CHANGELOG.md: %s\n  config.json:  %s", changelogVer, configVer)
+	}
+	return nil
+}
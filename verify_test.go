@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdVerifyChangelog(t *testing.T) {
+	p := setupProject(t, "v1.2.0")
+	changelog := "# Changelog\n\n## v1.2.0 - 2026-01-02\n\n- Fix\n\n## v1.1.0 - 2025-12-01\n\n- Feature\n"
+	os.WriteFile(filepath.Join(p.root, changelogFile), []byte(changelog), 0644)
+
+	output := captureStdout(func() {
+		if err := cmdVerifyChangelog(p); err != nil {
+			t.Fatalf("cmdVerifyChangelog failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "matches config.json (v1.2.0)") {
+		t.Errorf("unexpected output: %q", output)
+	}
+}
+
+func TestCmdVerifyChangelogMismatch(t *testing.T) {
+	p := setupProject(t, "v1.3.0")
+	os.WriteFile(filepath.Join(p.root, changelogFile), []byte("## [v1.2.0] - 2026-01-02\n\n- Fix\n"), 0644)
+
+	err := cmdVerifyChangelog(p)
+	if err == nil {
+		t.Fatal("expected error for mismatched versions")
+	}
+	if !strings.Contains(err.Error(), "CHANGELOG.md: v1.2.0") || !strings.Contains(err.Error(), "config.json:  v1.3.0") {
+		t.Errorf("expected both versions in the error, got %v", err)
+	}
+}
+
+func TestCompareChangelogVersion(t *testing.T) {
+	if err := compareChangelogVersion("", "v0.0.0"); err != nil {
+		t.Errorf("expected an unreleased project to pass, got %v", err)
+	}
+	if err := compareChangelogVersion("", "v1.0.0"); err == nil {
+		t.Error("expected error for a missing release section")
+	}
+	if err := compareChangelogVersion("v1.0.0", "v1.0.0"); err != nil {
+		t.Errorf("expected matching versions to pass, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// Versioning schemes accepted in the versioningScheme config field.
+const (
+	schemeSemver = "semver"
+	schemeCalver = "calver"
+)
+
+// versionIncrementer computes the version released after current, given the
+// highest bump among the pending changesets.
+type versionIncrementer func(current string, bump bumpType) (string, error)
+
+// versionIncrementerFor returns the incrementer for a versioning scheme. An
+// empty scheme selects semver. Calendar versions are taken from date, or from
+// today when date is zero, so a release given --date is reproducible.
+func versionIncrementerFor(scheme string, date time.Time) (versionIncrementer, error) {
+	switch scheme {
+	case "", schemeSemver:
+		return nextVersion, nil
+	case schemeCalver:
+		return func(current string, bump bumpType) (string, error) {
+			if bump == none {
+				return current, nil
+			}
+			if date.IsZero() {
+				date = time.Now()
+			}
+			return nextCalVersion(current, date)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown versioning scheme %q, expected %s or %s", scheme, schemeSemver, schemeCalver)
+	}
+}
+
+// nextVersionFor returns the version released after current for the given
+// changesets: current itself when there are none, and otherwise current with
+// the highest bump among them applied by inc. It does no IO, so release logic
+// can be tested on changesets built in memory.
+func nextVersionFor(current string, changes []*changeset, inc versionIncrementer) (string, error) {
+	if len(changes) == 0 {
+		return current, nil
+	}
+	return inc(current, highestBump(changes))
+}
+
+// prereleaseBump wraps inc so that every bump on a prerelease advances the
+// prerelease counter (v2.0.0-rc.1 -> v2.0.0-rc.2), even a major one. Versions
+// that are not prereleases are passed on to inc.
+func prereleaseBump(inc versionIncrementer) versionIncrementer {
+	return func(current string, bump bumpType) (string, error) {
+		ver, err := semver.NewVersion(strings.TrimPrefix(current, "v"))
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to parse current version %q: %w", ErrInvalidVersion, current, err)
+		}
+		if ver.Prerelease() == "" || bump == none {
+			return inc(current, bump)
+		}
+		next, err := ver.SetPrerelease(incPrerelease(ver.Prerelease()))
+		if err != nil {
+			return "", fmt.Errorf("failed to bump prerelease of %q: %w", current, err)
+		}
+		return "v" + next.String(), nil
+	}
+}
+
+// nextCalVersion returns the calendar version after current, in the form
+// vYYYY.M.N: the year and month of now, and a counter that starts at 0 and
+// increases with each release in the same month. The bump type is ignored.
+func nextCalVersion(current string, now time.Time) (string, error) {
+	ver, err := semver.NewVersion(strings.TrimPrefix(current, "v"))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to parse current version %q: %w", ErrInvalidVersion, current, err)
+	}
+
+	year, month := uint64(now.Year()), uint64(now.Month())
+	counter := uint64(0)
+	if ver.Major() == year && ver.Minor() == month {
+		counter = ver.Patch() + 1
+	}
+	return fmt.Sprintf("v%d.%d.%d", year, month, counter), nil
+}
+
+// nextVersion applies a bump to the current version string and returns the result
+// with a "v" prefix.
+//
+// When the current version is a prerelease, a patch bump advances the prerelease
+// counter (v1.2.0-rc.1 -> v1.2.0-rc.2) instead of finalizing the release.
+// Minor and major bumps finalize the prerelease and then bump it, moving on to
+// the next minor or major version (v1.2.0-rc.1 -> v2.0.0). Wrap with
+// prereleaseBump to advance the counter for those as well. A none bump leaves
+// the version unchanged.
+func nextVersion(current string, bump bumpType) (string, error) {
+	ver, err := semver.NewVersion(strings.TrimPrefix(current, "v"))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to parse current version %q: %w", ErrInvalidVersion, current, err)
+	}
+
+	var next semver.Version
+	switch {
+	case bump == none:
+		return current, nil
+	case ver.Prerelease() != "" && bump == patch:
+		next, err = ver.SetPrerelease(incPrerelease(ver.Prerelease()))
+		if err != nil {
+			return "", fmt.Errorf("failed to bump prerelease of %q: %w", current, err)
+		}
+	case bump == major:
+		next = ver.IncMajor()
+	case bump == minor:
+		next = ver.IncMinor()
+	default:
+		next = ver.IncPatch()
+	}
+
+	return "v" + next.String(), nil
+}
+
+// incPrerelease increments the trailing numeric identifier of a prerelease
+// ("rc.1" -> "rc.2"), appending ".1" when there is none ("rc" -> "rc.1").
+func incPrerelease(pre string) string {
+	parts := strings.Split(pre, ".")
+	last := parts[len(parts)-1]
+	if n, err := strconv.Atoi(last); err == nil {
+		parts[len(parts)-1] = strconv.Itoa(n + 1)
+		return strings.Join(parts, ".")
+	}
+	return pre + ".1"
+}
+
+// releaseVersionEnv lets release automation force the version of a release,
+// like release --version but without building flag strings.
+const releaseVersionEnv = "CHANGESETS_RELEASE_VERSION"
+
+// releaseVersionOverride returns the version a release should use instead of
+// the computed one: flagValue when set, otherwise $CHANGESETS_RELEASE_VERSION.
+// An empty result means no override; an invalid value is an ErrInvalidVersion
+// error naming where it came from.
+func releaseVersionOverride(flagValue string) (string, error) {
+	value, source := flagValue, "--version"
+	if value == "" {
+		value, source = os.Getenv(releaseVersionEnv), releaseVersionEnv
+	}
+	if value == "" {
+		return "", nil
+	}
+
+	ver, err := semver.NewVersion(strings.TrimPrefix(value, "v"))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %q is not a semantic version: %w", ErrInvalidVersion, source, value, err)
+	}
+	return "v" + ver.String(), nil
+}
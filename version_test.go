@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		current  string
+		bump     bumpType
+		expected string
+	}{
+		{"v1.0.0", patch, "v1.0.1"},
+		{"v1.0.0", minor, "v1.1.0"},
+		{"v1.0.0", major, "v2.0.0"},
+		{"1.0.0", patch, "v1.0.1"},
+		{"v1.2.0-rc.1", patch, "v1.2.0-rc.2"},
+		{"v1.2.0-rc", patch, "v1.2.0-rc.1"},
+		{"v1.2.0-beta.9", patch, "v1.2.0-beta.10"},
+		{"v1.2.0-rc.1", minor, "v1.3.0"},
+		{"v1.2.0-rc.1", major, "v2.0.0"},
+		{"v1.2.0", none, "v1.2.0"},
+		{"v1.2.0-rc.1", none, "v1.2.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		got, err := nextVersion(tt.current, tt.bump)
+		if err != nil {
+			t.Fatalf("nextVersion(%s, %s) failed: %v", tt.current, tt.bump, err)
+		}
+		if got != tt.expected {
+			t.Errorf("nextVersion(%s, %s) = %s, expected %s", tt.current, tt.bump, got, tt.expected)
+		}
+	}
+}
+
+func TestNextVersionFor(t *testing.T) {
+	cs := func(bumps ...bumpType) []*changeset {
+		var changes []*changeset
+		for _, b := range bumps {
+			changes = append(changes, &changeset{bump: b})
+		}
+		return changes
+	}
+
+	tests := []struct {
+		current  string
+		changes  []*changeset
+		expected string
+	}{
+		{"v1.0.0", nil, "v1.0.0"},
+		{"v1.0.0", cs(patch), "v1.0.1"},
+		{"v1.0.0", cs(patch, minor, patch), "v1.1.0"},
+		{"v1.0.0", cs(minor, major), "v2.0.0"},
+		{"v1.2.0-rc.1", cs(patch, patch), "v1.2.0-rc.2"},
+		{"v1.2.0-rc.1", cs(patch, minor), "v1.3.0"},
+	}
+
+	for _, tt := range tests {
+		got, err := nextVersionFor(tt.current, tt.changes, nextVersion)
+		if err != nil {
+			t.Fatalf("nextVersionFor(%s, %d changes) failed: %v", tt.current, len(tt.changes), err)
+		}
+		if got != tt.expected {
+			t.Errorf("nextVersionFor(%s, %d changes) = %s, expected %s", tt.current, len(tt.changes), got, tt.expected)
+		}
+	}
+
+	if _, err := nextVersionFor("not-a-version", cs(patch), nextVersion); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
+
+func TestNextVersionInvalid(t *testing.T) {
+	if _, err := nextVersion("not-a-version", patch); err == nil {
+		t.Fatal("expected error for invalid version")
+	}
+}
+
+func TestNextCalVersion(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		current  string
+		expected string
+	}{
+		{"v0.0.0", "v2026.10.0"},
+		{"v2026.10.0", "v2026.10.1"},
+		{"v2026.10.4", "v2026.10.5"},
+		{"v2026.9.3", "v2026.10.0"},
+		{"v2025.10.3", "v2026.10.0"},
+	}
+
+	for _, tt := range tests {
+		got, err := nextCalVersion(tt.current, now)
+		if err != nil {
+			t.Fatalf("nextCalVersion(%s) failed: %v", tt.current, err)
+		}
+		if got != tt.expected {
+			t.Errorf("nextCalVersion(%s) = %s, expected %s", tt.current, got, tt.expected)
+		}
+	}
+
+	if _, err := nextCalVersion("not-a-version", now); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
+
+func TestVersionIncrementerFor(t *testing.T) {
+	for _, scheme := range []string{"", schemeSemver} {
+		inc, err := versionIncrementerFor(scheme, time.Time{})
+		if err != nil {
+			t.Fatalf("versionIncrementerFor(%q) failed: %v", scheme, err)
+		}
+		if got, _ := inc("v1.0.0", minor); got != "v1.1.0" {
+			t.Errorf("expected semver increment for %q, got %s", scheme, got)
+		}
+	}
+
+	inc, err := versionIncrementerFor(schemeCalver, time.Time{})
+	if err != nil {
+		t.Fatalf("versionIncrementerFor(calver) failed: %v", err)
+	}
+	now := time.Now()
+	if got, _ := inc("v1.0.0", major); got != fmt.Sprintf("v%d.%d.0", now.Year(), now.Month()) {
+		t.Errorf("expected calendar version, got %s", got)
+	}
+
+	date := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	if inc, err = versionIncrementerFor(schemeCalver, date); err != nil {
+		t.Fatalf("versionIncrementerFor(calver) failed: %v", err)
+	}
+	if got, _ := inc("v1.0.0", patch); got != "v2024.1.0" {
+		t.Errorf("expected the calendar version of the release date, got %s", got)
+	}
+
+	if _, err := versionIncrementerFor("romver", time.Time{}); err == nil {
+		t.Error("expected error for unknown scheme")
+	}
+}
+
+func TestIncPrerelease(t *testing.T) {
+	tests := []struct {
+		pre      string
+		expected string
+	}{
+		{"rc.1", "rc.2"},
+		{"rc", "rc.1"},
+		{"alpha.beta", "alpha.beta.1"},
+		{"1", "2"},
+	}
+
+	for _, tt := range tests {
+		if got := incPrerelease(tt.pre); got != tt.expected {
+			t.Errorf("incPrerelease(%q) = %q, expected %q", tt.pre, got, tt.expected)
+		}
+	}
+}
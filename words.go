@@ -6,8 +6,12 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode"
 )
 
+const defaultSlugSeparator = "-"
+
 var adjectives = []string{
 	"angry", "brave", "calm", "dark", "eager",
 	"fair", "glad", "happy", "icy", "jolly",
@@ -55,9 +59,10 @@ var nouns = []string{
 	"hops", "ink", "jet", "key", "log",
 }
 
-// generateSlug creates a random slug in the format "adj-adj-noun".
-// It checks for collisions with existing files in changesDir.
-func generateSlug(dir string) (string, error) {
+// generateSlug creates a random slug in the format "adj<sep>adj<sep>noun",
+// or "prefix<sep>adj<sep>adj<sep>noun" when prefix is set. It checks for
+// collisions with existing files in dir.
+func generateSlug(dir, sep, prefix string) (string, error) {
 	for attempts := 0; attempts < 100; attempts++ {
 		adj1, err := randomElement(adjectives)
 		if err != nil {
@@ -72,7 +77,11 @@ func generateSlug(dir string) (string, error) {
 			return "", err
 		}
 
-		slug := fmt.Sprintf("%s-%s-%s", adj1, adj2, noun)
+		words := []string{adj1, adj2, noun}
+		if prefix != "" {
+			words = append([]string{prefix}, words...)
+		}
+		slug := strings.Join(words, sep)
 		filename := slug + ".md"
 
 		path := filepath.Join(dir, filename)
@@ -84,6 +93,26 @@ func generateSlug(dir string) (string, error) {
 	return "", fmt.Errorf("failed to generate unique slug after 100 attempts")
 }
 
+// sanitizeSlugPrefix turns a package name such as "yaml.v3" or "my_pkg" into
+// a slug prefix that passes checkSlugFilename: it is lowercased and every run
+// of other characters becomes a single sep.
+func sanitizeSlugPrefix(name, sep string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pending && b.Len() > 0 {
+				b.WriteString(sep)
+			}
+			pending = false
+			b.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+	return b.String()
+}
+
 func randomElement(slice []string) (string, error) {
 	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(slice))))
 	if err != nil {
@@ -96,3 +125,54 @@ func randomElement(slice []string) (string, error) {
 func slugToFilename(slug string) string {
 	return slug + ".md"
 }
+
+// filenameToSlug converts a markdown filename back to its slug.
+// The separator is kept as-is, so slugs round-trip regardless of configuration.
+func filenameToSlug(filename string) string {
+	return strings.TrimSuffix(filename, ".md")
+}
+
+// validateSlugSeparator checks that sep is a single character that is safe to use
+// in filenames on all common filesystems and does not blend into the words.
+func validateSlugSeparator(sep string) error {
+	runes := []rune(sep)
+	if len(runes) != 1 {
+		return fmt.Errorf("invalid slug separator %q, expected a single character", sep)
+	}
+
+	r := runes[0]
+	if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) ||
+		unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(`/\:*?"<>|.`, r) {
+		return fmt.Errorf("invalid slug separator %q, expected a filesystem-safe punctuation character", sep)
+	}
+
+	return nil
+}
+
+// checkSlugFilename reports why a changeset filename would not have been
+// produced by add: slugs are lowercase letters and digits joined by sep, with
+// no leading, trailing or doubled separators. A nil error means the name is fine.
+func checkSlugFilename(filename, sep string) error {
+	slug := filenameToSlug(filename)
+	if slug == "" {
+		return fmt.Errorf("has an empty slug")
+	}
+	for _, r := range slug {
+		switch {
+		case unicode.IsSpace(r):
+			return fmt.Errorf("contains whitespace")
+		case unicode.IsUpper(r):
+			return fmt.Errorf("contains uppercase letters")
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || string(r) == sep:
+		default:
+			return fmt.Errorf("contains %q, expected only lowercase letters, digits and %q", r, sep)
+		}
+	}
+	if strings.HasPrefix(slug, sep) || strings.HasSuffix(slug, sep) {
+		return fmt.Errorf("starts or ends with %q", sep)
+	}
+	if strings.Contains(slug, sep+sep) {
+		return fmt.Errorf("contains a doubled %q", sep)
+	}
+	return nil
+}
@@ -47,7 +47,7 @@ func withReader(r io.Reader, fn func()) {
 func TestGenerateSlug(t *testing.T) {
 	dir := t.TempDir()
 
-	slug, err := generateSlug(dir)
+	slug, err := generateSlug(dir, defaultSlugSeparator, "")
 	if err != nil {
 		t.Fatalf("generateSlug failed: %v", err)
 	}
@@ -69,7 +69,7 @@ func TestGenerateSlugUniqueness(t *testing.T) {
 
 	seen := make(map[string]bool)
 	for i := 0; i < 50; i++ {
-		slug, err := generateSlug(dir)
+		slug, err := generateSlug(dir, defaultSlugSeparator, "")
 		if err != nil {
 			t.Fatalf("generateSlug failed on iteration %d: %v", i, err)
 		}
@@ -86,7 +86,7 @@ func TestGenerateSlugAvoidsExisting(t *testing.T) {
 	dir := t.TempDir()
 
 	// Generate one slug, create the file, then generate another
-	slug1, err := generateSlug(dir)
+	slug1, err := generateSlug(dir, defaultSlugSeparator, "")
 	if err != nil {
 		t.Fatalf("generateSlug failed: %v", err)
 	}
@@ -97,7 +97,7 @@ func TestGenerateSlugAvoidsExisting(t *testing.T) {
 	}
 
 	// Generate another slug - should be different
-	slug2, err := generateSlug(dir)
+	slug2, err := generateSlug(dir, defaultSlugSeparator, "")
 	if err != nil {
 		t.Fatalf("generateSlug failed: %v", err)
 	}
@@ -113,6 +113,104 @@ func TestSlugToFilename(t *testing.T) {
 	}
 }
 
+func TestFilenameToSlug(t *testing.T) {
+	if got := filenameToSlug("brave-orange-fox.md"); got != "brave-orange-fox" {
+		t.Errorf("expected brave-orange-fox, got %s", got)
+	}
+	if got := filenameToSlug("brave_orange_fox.md"); got != "brave_orange_fox" {
+		t.Errorf("expected brave_orange_fox, got %s", got)
+	}
+}
+
+func TestGenerateSlugCustomSeparator(t *testing.T) {
+	slug, err := generateSlug(t.TempDir(), "_", "")
+	if err != nil {
+		t.Fatalf("generateSlug failed: %v", err)
+	}
+
+	parts := strings.Split(slug, "_")
+	if len(parts) != 3 {
+		t.Errorf("expected 3 underscore-separated parts, got %q", slug)
+	}
+	if strings.Contains(slug, "-") {
+		t.Errorf("expected no dashes in slug, got %q", slug)
+	}
+}
+
+func TestGenerateSlugPrefix(t *testing.T) {
+	slug, err := generateSlug(t.TempDir(), "_", "api")
+	if err != nil {
+		t.Fatalf("generateSlug failed: %v", err)
+	}
+
+	parts := strings.Split(slug, "_")
+	if len(parts) != 4 || parts[0] != "api" {
+		t.Errorf("expected api followed by 3 words, got %q", slug)
+	}
+	if got := filenameToSlug(slugToFilename(slug)); got != slug {
+		t.Errorf("expected prefixed slug to round-trip, got %q", got)
+	}
+}
+
+func TestSanitizeSlugPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  string
+		want string
+	}{
+		{"api", "-", "api"},
+		{"yaml.v3", "-", "yaml-v3"},
+		{"my_pkg", "_", "my_pkg"},
+		{"My__Pkg", "-", "my-pkg"},
+		{".hidden.", "-", "hidden"},
+		{"...", "-", ""},
+	}
+	for _, tt := range tests {
+		got := sanitizeSlugPrefix(tt.name, tt.sep)
+		if got != tt.want {
+			t.Errorf("sanitizeSlugPrefix(%q, %q) = %q, want %q", tt.name, tt.sep, got, tt.want)
+		}
+		if got == "" {
+			continue
+		}
+		if err := checkSlugFilename(got+tt.sep+"brave"+tt.sep+"fox.md", tt.sep); err != nil {
+			t.Errorf("sanitizeSlugPrefix(%q, %q) = %q fails checkSlugFilename: %v", tt.name, tt.sep, got, err)
+		}
+	}
+}
+
+func TestValidateSlugSeparator(t *testing.T) {
+	tests := []struct {
+		sep   string
+		valid bool
+	}{
+		{"-", true},
+		{"_", true},
+		{"+", true},
+		{"~", true},
+		{"", false},
+		{"--", false},
+		{"a", false},
+		{"1", false},
+		{" ", false},
+		{"/", false},
+		{"\\", false},
+		{":", false},
+		{".", false},
+		{"é", false},
+	}
+
+	for _, tt := range tests {
+		err := validateSlugSeparator(tt.sep)
+		if tt.valid && err != nil {
+			t.Errorf("validateSlugSeparator(%q) unexpected error: %v", tt.sep, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("validateSlugSeparator(%q) expected error, got nil", tt.sep)
+		}
+	}
+}
+
 func TestRandomElementError(t *testing.T) {
 	withReader(&countingFailReader{maxReads: 0}, func() {
 		_, err := randomElement(adjectives)
@@ -127,7 +225,7 @@ func TestGenerateSlugExhaustion(t *testing.T) {
 
 	// Use a zero reader so the slug is always the same deterministic value.
 	withReader(zeroReader{}, func() {
-		slug, err := generateSlug(dir)
+		slug, err := generateSlug(dir, defaultSlugSeparator, "")
 		if err != nil {
 			t.Fatalf("first generateSlug failed: %v", err)
 		}
@@ -137,7 +235,7 @@ func TestGenerateSlugExhaustion(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, err = generateSlug(dir)
+		_, err = generateSlug(dir, defaultSlugSeparator, "")
 		if err == nil {
 			t.Error("expected error after 100 collision attempts, got nil")
 		}
@@ -147,7 +245,7 @@ func TestGenerateSlugExhaustion(t *testing.T) {
 func TestGenerateSlugRandomElementFailFirstCall(t *testing.T) {
 	dir := t.TempDir()
 	withReader(&countingFailReader{maxReads: 0}, func() {
-		_, err := generateSlug(dir)
+		_, err := generateSlug(dir, defaultSlugSeparator, "")
 		if err == nil {
 			t.Error("expected error when first randomElement fails")
 		}
@@ -157,7 +255,7 @@ func TestGenerateSlugRandomElementFailFirstCall(t *testing.T) {
 func TestGenerateSlugRandomElementFailSecondCall(t *testing.T) {
 	dir := t.TempDir()
 	withReader(&countingFailReader{maxReads: 1}, func() {
-		_, err := generateSlug(dir)
+		_, err := generateSlug(dir, defaultSlugSeparator, "")
 		if err == nil {
 			t.Error("expected error when second randomElement fails")
 		}
@@ -167,9 +265,39 @@ func TestGenerateSlugRandomElementFailSecondCall(t *testing.T) {
 func TestGenerateSlugRandomElementFailThirdCall(t *testing.T) {
 	dir := t.TempDir()
 	withReader(&countingFailReader{maxReads: 2}, func() {
-		_, err := generateSlug(dir)
+		_, err := generateSlug(dir, defaultSlugSeparator, "")
 		if err == nil {
 			t.Error("expected error when third randomElement fails")
 		}
 	})
 }
+
+func TestCheckSlugFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		sep      string
+		valid    bool
+	}{
+		{"brave-orange-fox.md", "-", true},
+		{"api-brave-orange-fox.md", "-", true},
+		{"fix-issue-42.md", "-", true},
+		{"brave_orange_fox.md", "_", true},
+		{"Brave-orange-fox.md", "-", false},
+		{"brave orange fox.md", "-", false},
+		{"brave_orange-fox.md", "-", false},
+		{"-brave-fox.md", "-", false},
+		{"brave-fox-.md", "-", false},
+		{"brave--fox.md", "-", false},
+		{".md", "-", false},
+	}
+
+	for _, tt := range tests {
+		err := checkSlugFilename(tt.filename, tt.sep)
+		if tt.valid && err != nil {
+			t.Errorf("checkSlugFilename(%q, %q) unexpected error: %v", tt.filename, tt.sep, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("checkSlugFilename(%q, %q) expected error, got nil", tt.filename, tt.sep)
+		}
+	}
+}
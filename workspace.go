@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const workspaceFile = "go.work"
+
+// workspacePackages reads go.work in root and maps each member module's
+// package name (see moduleName) to its directory.
+func workspacePackages(root string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, workspaceFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", workspaceFile, err)
+	}
+
+	packages := make(map[string]string)
+	for _, dir := range parseWorkspaceUses(string(data)) {
+		dir = filepath.Join(root, filepath.FromSlash(dir))
+		name, err := moduleName(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workspace member %s: %w", dir, err)
+		}
+		packages[name] = dir
+	}
+
+	return packages, nil
+}
+
+// parseWorkspaceUses extracts the directories listed by "use" directives in a
+// go.work file, supporting both the single-line and block forms.
+func parseWorkspaceUses(content string) []string {
+	var dirs []string
+	inBlock := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock:
+			dirs = append(dirs, strings.Trim(line, `"`))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+
+	return dirs
+}
+
+// packageChanges narrows changesets to those referencing the named package,
+// each carrying the bump it declares for that package.
+func packageChanges(changes []*changeset, name string) []*changeset {
+	var selected []*changeset
+	for _, cs := range changes {
+		if b, ok := cs.bumpFor(name); ok {
+			pkgChange := *cs
+			pkgChange.bump = b
+			selected = append(selected, &pkgChange)
+		}
+	}
+	return selected
+}
+
+// nextPackageVersion computes the next version of a single package from the
+// pending changesets. Without changesets for it, the current version is returned.
+// allowPrereleaseBump and date work as in calculateNextVersion.
+func nextPackageVersion(cfg *config, changes []*changeset, name string, allowPrereleaseBump bool, date time.Time) (string, error) {
+	inc, err := cfg.versionIncrementer(allowPrereleaseBump, date)
+	if err != nil {
+		return "", err
+	}
+	return nextVersionFor(cfg.packageVersion(name), packageChanges(changes, name), inc)
+}
+
+// nextPackageVersions computes the next version of every package tracked in
+// cfg.Packages.
+func nextPackageVersions(cfg *config, changes []*changeset) (map[string]string, error) {
+	versions := make(map[string]string, len(cfg.Packages))
+	for name := range cfg.Packages {
+		next, err := nextPackageVersion(cfg, changes, name, false, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", name, err)
+		}
+		versions[name] = next
+	}
+	return versions, nil
+}
+
+// seedPackages returns the initial per-package versions for a new project: one
+// v0.0.0 entry per go.work member, or nil when root is not a workspace.
+func seedPackages(root string) (map[string]string, error) {
+	members, err := workspacePackages(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string]string, len(members))
+	for name := range members {
+		packages[name] = "v0.0.0"
+	}
+	return packages, nil
+}
+
+// releasePackage releases a single workspace package. Only changesets that
+// reference the package are considered, and only its version and CHANGELOG.md
+// are updated; other packages' entries stay on disk for a later release.
+func releasePackage(p paths, scanner *bufio.Scanner, opts releaseOptions) error {
+	result, err := releasePackageVersion(p, scanner, opts)
+	if err != nil || result == nil || opts.output == "-" {
+		return err
+	}
+	return printReleaseResult(*result, opts.json, prettyJSON(opts.pretty))
+}
+
+// releasePackageVersion performs the release of opts.pkg and returns its
+// result, or nil when the release was declined at the interactive prompt or,
+// with opts.quiet, there was nothing to release.
+func releasePackageVersion(p paths, scanner *bufio.Scanner, opts releaseOptions) (*releaseResult, error) {
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := workspacePackages(p.root)
+	if err != nil {
+		return nil, err
+	}
+	dir, ok := packages[opts.pkg]
+	if !ok {
+		return nil, fmt.Errorf("package %s is not a member of %s", opts.pkg, workspaceFile)
+	}
+
+	parseOpts := cfg.parseOptions()
+	parseOpts.lenientBump = opts.lenientBump
+	changes, err := listChangesets(p.changes, parseOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := packageChanges(changes, opts.pkg)
+	if len(selected) == 0 {
+		if opts.quiet {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w for package %s, nothing to release", ErrNoChangesets, opts.pkg)
+	}
+	if highestBump(selected) == none {
+		return nil, noVersionChange(opts)
+	}
+
+	printDroppedSummaries(os.Stderr, selected, cfg.SummaryRender)
+
+	previous := cfg.packageVersion(opts.pkg)
+	date, _ := time.Parse(releaseDateLayout, opts.date) // validated by parseReleaseFlags
+	nextVerStr, err := nextPackageVersion(cfg, selected, opts.pkg, opts.allowPrereleaseBump, date)
+	if err != nil {
+		return nil, err
+	}
+
+	changelogOpts := changelogOptions{showAuthors: cfg.ShowAuthors, showSubjects: cfg.ShowCommitSubject, summaryRender: cfg.SummaryRender}
+	changelogOpts.date = date
+	if changelogOpts.template, err = loadChangelogTemplate(p.root, cfg, opts.templateFile); err != nil {
+		return nil, err
+	}
+	changelogSection, err := buildChangelogSection(nextVerStr, selected, changelogOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGitTimeout(os.Stderr, cfg); err != nil {
+		return nil, err
+	}
+
+	if opts.interactive {
+		ok, err := confirmRelease(scanner, nextVerStr, changelogSection)
+		if err != nil || !ok {
+			return nil, err
+		}
+	}
+
+	// --output only renders the section; the package is not released
+	if opts.output != "" {
+		if err := writeReleaseNotes(opts.output, changelogSection); err != nil {
+			return nil, err
+		}
+		return &releaseResult{Package: opts.pkg, Previous: previous, Version: nextVerStr, ChangelogPath: opts.output}, nil
+	}
+
+	changelogPath := filepath.Join(dir, changelogFile)
+	if err := prependChangelog(changelogPath, changelogSection, cfg.changelogHeading(), opts.replace, cfg.MaxChangelogSections); err != nil {
+		return nil, err
+	}
+
+	if cfg.Packages == nil {
+		cfg.Packages = make(map[string]string)
+	}
+	cfg.Packages[opts.pkg] = nextVerStr
+	if err := saveConfig(p.config, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := appendStepSummary(changelogSection); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaintainIndex {
+		if err := appendIndex(p, newIndexEntries(nextVerStr, opts.pkg, changelogOpts.date, selected)); err != nil {
+			return nil, err
+		}
+	}
+
+	removed := len(selected)
+	if !opts.keep {
+		removed, err = removePackageReleases(selected, opts.pkg)
+		if err := checkPackageCleanup(p.changes, parseOpts, opts.pkg, nextVerStr, err); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.quiet {
+		printCleanupSummary(os.Stderr, removed, opts.keep)
+	}
+
+	relPath, err := filepath.Rel(p.root, changelogPath)
+	if err != nil {
+		relPath = changelogPath
+	}
+
+	return &releaseResult{
+		Package:       opts.pkg,
+		Previous:      previous,
+		Version:       nextVerStr,
+		ChangelogPath: filepath.ToSlash(relPath),
+		Consumed:      len(selected),
+	}, nil
+}
+
+// releaseAllPackages releases every workspace package that has pending
+// changesets, one after another, and prints a table of the new versions.
+func releaseAllPackages(p paths, scanner *bufio.Scanner, opts releaseOptions) error {
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		return err
+	}
+
+	packages, err := workspacePackages(p.root)
+	if err != nil {
+		return err
+	}
+
+	parseOpts := cfg.parseOptions()
+	parseOpts.lenientBump = opts.lenientBump
+	changes, err := listChangesets(p.changes, parseOpts)
+	if err != nil {
+		return err
+	}
+
+	// Packages with only none changesets are left out up front, since their
+	// release would fail after the others were already written.
+	names := make([]string, 0, len(packages))
+	notesOnly := false
+	for name := range packages {
+		selected := packageChanges(changes, name)
+		switch {
+		case len(selected) == 0:
+		case highestBump(selected) == none:
+			notesOnly = true
+		default:
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		if notesOnly {
+			return noVersionChange(opts)
+		}
+		if opts.quiet {
+			return nil
+		}
+		return fmt.Errorf("%w for any workspace package, nothing to release", ErrNoChangesets)
+	}
+	sort.Strings(names)
+
+	var results []releaseResult
+	for _, name := range names {
+		pkgOpts := opts
+		pkgOpts.pkg = name
+		result, err := releasePackageVersion(p, scanner, pkgOpts)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", name, err)
+		}
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	return printReleaseResults(os.Stdout, results, opts.json, prettyJSON(opts.pretty))
+}
+
+// printReleaseResults writes one row per released package, or the results as
+// a JSON array.
+func printReleaseResults(w io.Writer, results []releaseResult, asJSON, pretty bool) error {
+	if asJSON {
+		if results == nil {
+			results = []releaseResult{}
+		}
+		if err := writeJSON(w, results, pretty); err != nil {
+			return fmt.Errorf("failed to write release results: %w", err)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tPREVIOUS\tVERSION")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Package, r.Previous, r.Version)
+	}
+	return tw.Flush()
+}
+
+// removePackageReleases drops the named package from each changeset file,
+// deleting files that no longer reference any package, and returns how many
+// files were deleted.
+func removePackageReleases(changes []*changeset, name string) (int, error) {
+	removed := 0
+	for _, cs := range changes {
+		var remaining []release
+		for _, r := range cs.releases {
+			if r.name != name {
+				remaining = append(remaining, r)
+			}
+		}
+
+		if len(remaining) == 0 {
+			if err := removeFile(cs.filepath); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", filepath.Base(cs.filepath), err)
+			}
+			removed++
+			continue
+		}
+
+		cs.releases = remaining
+		if err := os.WriteFile(cs.filepath, []byte(cs.content()), 0644); err != nil {
+			return removed, fmt.Errorf("failed to rewrite %s: %w", filepath.Base(cs.filepath), err)
+		}
+	}
+
+	return removed, nil
+}
+
+// checkPackageCleanup is checkCleanup for a release of the named package:
+// changesets for other packages stay pending, so only those still bumping
+// name count as left over.
+func checkPackageCleanup(dir string, opts parseOptions, name, ver string, cleanupErr error) error {
+	changes, _, err := collectChangesets(dir, opts)
+	if err != nil {
+		return err
+	}
+
+	var left []string
+	for _, cs := range packageChanges(changes, name) {
+		left = append(left, filepath.Base(cs.filepath))
+	}
+	return leftoverError(name+"@"+ver, left, cleanupErr)
+}
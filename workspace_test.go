@@ -0,0 +1,514 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupWorkspace turns a project into a go.work workspace with one member
+// module per name, each in a directory of the same name.
+func setupWorkspace(t *testing.T, p paths, names ...string) {
+	t.Helper()
+
+	work := "go 1.25.0\n\nuse (\n"
+	for _, name := range names {
+		dir := filepath.Join(p.root, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		gomod := "module example.com/" + name + "\n\ngo 1.25.0\n"
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		work += "\t./" + name + "\n"
+	}
+	work += ")\n"
+
+	if err := os.WriteFile(filepath.Join(p.root, workspaceFile), []byte(work), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseWorkspaceUses(t *testing.T) {
+	content := `go 1.25.0
+
+use ./tools // single line
+
+use (
+	./api
+	"./web"
+	// ./disabled
+)
+`
+	got := parseWorkspaceUses(content)
+	want := []string{"./tools", "./api", "./web"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWorkspacePackages(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	setupWorkspace(t, p, "api", "web")
+
+	packages, err := workspacePackages(p.root)
+	if err != nil {
+		t.Fatalf("workspacePackages failed: %v", err)
+	}
+	if packages["api"] != filepath.Join(p.root, "api") {
+		t.Errorf("unexpected api dir %q", packages["api"])
+	}
+	if packages["web"] != filepath.Join(p.root, "web") {
+		t.Errorf("unexpected web dir %q", packages["web"])
+	}
+}
+
+func TestWorkspacePackagesMissing(t *testing.T) {
+	if _, err := workspacePackages(t.TempDir()); err == nil {
+		t.Fatal("expected error without go.work")
+	}
+}
+
+func TestCmdReleasePackage(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: minor\n---\n\nAPI feature",
+		"---\nweb: major\n---\n\nWeb rewrite",
+		"---\napi: patch\nweb: patch\n---\n\nShared fix",
+	)
+	setupWorkspace(t, p, "api", "web")
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{pkg: "api"}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "v0.1.0" {
+		t.Errorf("expected v0.1.0, got %q", output)
+	}
+
+	changelog, err := os.ReadFile(filepath.Join(p.root, "api", changelogFile))
+	if err != nil {
+		t.Fatalf("expected api changelog: %v", err)
+	}
+	for _, want := range []string{"## v0.1.0", "API feature", "Shared fix"} {
+		if !strings.Contains(string(changelog), want) {
+			t.Errorf("api changelog missing %q:\n%s", want, changelog)
+		}
+	}
+	if strings.Contains(string(changelog), "Web rewrite") {
+		t.Error("api changelog should not include web changes")
+	}
+	if _, err := os.Stat(filepath.Join(p.root, changelogFile)); !os.IsNotExist(err) {
+		t.Error("root changelog should not be written")
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Version != "v1.0.0" {
+		t.Errorf("expected root version untouched, got %s", cfg.Version)
+	}
+	if cfg.Packages["api"] != "v0.1.0" {
+		t.Errorf("expected api version v0.1.0, got %q", cfg.Packages["api"])
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{maxSize: defaultMaxChangesetSize})
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 remaining changesets, got %d", len(changes))
+	}
+	for _, cs := range changes {
+		if _, ok := cs.bumpFor("api"); ok {
+			t.Errorf("changeset %s still references api", cs.slug)
+		}
+	}
+}
+
+func TestCmdReleasePackageJSON(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\napi: patch\n---\n\nFix")
+	setupWorkspace(t, p, "api")
+
+	cfg, _ := loadConfig(p.config)
+	cfg.Packages = map[string]string{"api": "v2.0.0"}
+	saveConfig(p.config, cfg)
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{pkg: "api", json: true}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+
+	var result releaseResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if result.Previous != "v2.0.0" || result.Version != "v2.0.1" {
+		t.Errorf("unexpected versions %+v", result)
+	}
+	if result.ChangelogPath != "api/CHANGELOG.md" || result.Consumed != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestCmdReleasePackageOutput(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\napi: minor\n---\n\nNew endpoint")
+	setupWorkspace(t, p, "api")
+	notes := filepath.Join(p.root, "notes.md")
+
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{pkg: "api", output: notes}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+
+	if data, _ := os.ReadFile(notes); !strings.Contains(string(data), "New endpoint") {
+		t.Errorf("expected the section in %s, got %q", notes, data)
+	}
+	cfg, _ := loadConfig(p.config)
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if cfg.Packages["api"] != "" || len(changes) != 1 {
+		t.Errorf("expected --output to release nothing, got packages %v and %d pending changesets", cfg.Packages, len(changes))
+	}
+}
+
+func TestCmdReleasePackageCleanupFails(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: patch\n---\n\nFixed bug",
+		"---\napi: patch\n---\n\nFixed typo",
+		"---\nweb: patch\n---\n\nWeb fix",
+	)
+	setupWorkspace(t, p, "api", "web")
+	orig := removeFile
+	defer func() { removeFile = orig }()
+	removeFile = func(path string) error {
+		if filepath.Base(path) == "change-1.md" {
+			return os.ErrPermission
+		}
+		return os.Remove(path)
+	}
+
+	var err error
+	captureStdout(func() {
+		err = cmdRelease(p, newScanner(""), releaseOptions{pkg: "api"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "released api@v0.0.1, but these changesets are still pending: change-1.md;") {
+		t.Fatalf("expected an error naming only the leftover api changeset, got %v", err)
+	}
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected the removal error to be wrapped, got %v", err)
+	}
+}
+
+func TestRemovePackageReleasesCount(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: patch\n---\n\nFixed bug",
+		"---\napi: patch\nweb: patch\n---\n\nShared fix",
+	)
+	changes, err := listChangesets(p.changes, parseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shared changeset is rewritten for web, not removed.
+	removed, err := removePackageReleases(changes, "api")
+	if err != nil || removed != 1 {
+		t.Errorf("expected 1 file removed, got %d (%v)", removed, err)
+	}
+	if left, _ := listChangesets(p.changes, parseOptions{}); len(left) != 1 || left[0].releases[0].name != "web" {
+		t.Errorf("expected only the web release to remain, got %v", left)
+	}
+}
+
+func TestCmdReleaseAll(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: minor\n---\n\nAPI feature",
+		"---\nweb: major\n---\n\nWeb rewrite",
+		"---\napi: patch\nweb: patch\n---\n\nShared fix",
+		"---\ntest: patch\n---\n\nRoot fix",
+	)
+	setupWorkspace(t, p, "api", "web", "cli")
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{all: true}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	for _, want := range []string{"PACKAGE  PREVIOUS  VERSION", "api      v0.0.0    v0.1.0", "web      v0.0.0    v1.0.0"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in summary, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "cli") {
+		t.Errorf("packages without changesets should not be released, got:\n%s", output)
+	}
+
+	cfg, _ := loadConfig(p.config)
+	if cfg.Packages["api"] != "v0.1.0" || cfg.Packages["web"] != "v1.0.0" || cfg.Version != "v1.0.0" {
+		t.Errorf("unexpected versions: root %s, packages %v", cfg.Version, cfg.Packages)
+	}
+	for _, name := range []string{"api", "web"} {
+		changelog, _ := os.ReadFile(filepath.Join(p.root, name, changelogFile))
+		if !strings.Contains(string(changelog), "Shared fix") {
+			t.Errorf("%s changelog missing the shared fix:\n%s", name, changelog)
+		}
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].summary != "Root fix" {
+		t.Errorf("expected only the root changeset to remain, got %v", changes)
+	}
+}
+
+func TestCmdReleaseAllJSON(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\napi: patch\n---\n\nFix")
+	setupWorkspace(t, p, "api", "web")
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{all: true, json: true}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+
+	var results []releaseResult
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if len(results) != 1 || results[0].Package != "api" || results[0].Version != "v0.0.1" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestCmdReleaseAllSkipsNoneOnly(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: minor\n---\n\nAPI feature",
+		"---\nweb: none\n---\n\nWeb docs note",
+	)
+	setupWorkspace(t, p, "api", "web")
+
+	output := captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{all: true}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "api      v0.0.0    v0.1.0") || strings.Contains(output, "web") {
+		t.Errorf("expected only api to be released, got:\n%s", output)
+	}
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || changes[0].summary != "Web docs note" {
+		t.Errorf("expected the web note to stay pending, got %v", changes)
+	}
+
+	if err := cmdRelease(p, newScanner(""), releaseOptions{all: true}); err == nil || !strings.Contains(err.Error(), "only none changesets") {
+		t.Errorf("expected a notes-only --all release to fail, got %v", err)
+	}
+}
+
+func TestCmdReleaseAllNoChangesets(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\ntest: patch\n---\n\nRoot fix")
+	setupWorkspace(t, p, "api")
+
+	if err := cmdRelease(p, newScanner(""), releaseOptions{all: true}); !errors.Is(err, ErrNoChangesets) {
+		t.Fatalf("expected ErrNoChangesets, got %v", err)
+	}
+}
+
+func TestParseReleaseFlagsAll(t *testing.T) {
+	if _, err := parseReleaseFlags([]string{"--all", "--package", "api"}); err == nil {
+		t.Error("expected error combining --all with --package")
+	}
+	if _, err := parseReleaseFlags([]string{"--all", "--output", "-"}); err == nil {
+		t.Error("expected error combining --all with --output")
+	}
+}
+
+func TestCmdReleasePackageNotMember(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\napi: patch\n---\n\nFix")
+	setupWorkspace(t, p, "web")
+
+	if err := cmdRelease(p, newScanner(""), releaseOptions{pkg: "api"}); err == nil {
+		t.Fatal("expected error for package outside the workspace")
+	}
+}
+
+func TestCmdReleasePackageNoChangesets(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\nweb: patch\n---\n\nFix")
+	setupWorkspace(t, p, "api", "web")
+
+	err := cmdRelease(p, newScanner(""), releaseOptions{pkg: "api"})
+	if err == nil {
+		t.Fatal("expected error when no changesets reference the package")
+	}
+	if !errors.Is(err, ErrNoChangesets) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNextPackageVersions(t *testing.T) {
+	cfg := &config{Version: "v1.0.0", Packages: map[string]string{"api": "v1.2.0", "web": "v0.3.1"}}
+	changes := []*changeset{
+		{releases: []release{{name: "api", bump: minor}, {name: "web", bump: patch}}},
+		{releases: []release{{name: "api", bump: patch}}},
+	}
+
+	versions, err := nextPackageVersions(cfg, changes)
+	if err != nil {
+		t.Fatalf("nextPackageVersions failed: %v", err)
+	}
+	if versions["api"] != "v1.3.0" {
+		t.Errorf("expected api v1.3.0, got %s", versions["api"])
+	}
+	if versions["web"] != "v0.3.2" {
+		t.Errorf("expected web v0.3.2, got %s", versions["web"])
+	}
+
+	cfg.Packages["cli"] = "v2.0.0"
+	versions, _ = nextPackageVersions(cfg, changes)
+	if versions["cli"] != "v2.0.0" {
+		t.Errorf("expected cli without changesets to keep v2.0.0, got %s", versions["cli"])
+	}
+}
+
+func TestCmdReleasePackageAllowPrereleaseBump(t *testing.T) {
+	p := setupProject(t, "v1.0.0", "---\napi: major\n---\n\nBreaking change")
+	setupWorkspace(t, p, "api")
+	saveConfig(p.config, &config{Version: "v1.0.0", Packages: map[string]string{"api": "v2.0.0-rc.1"}})
+
+	output := captureStdout(func() {
+		if err := cmdNext(p, nextOptions{pkg: "api", allowPrereleaseBump: true}); err != nil {
+			t.Fatalf("cmdNext failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "v2.0.0-rc.2" {
+		t.Errorf("expected next --package to advance the counter, got %q", output)
+	}
+
+	captureStdout(func() {
+		if err := cmdRelease(p, newScanner(""), releaseOptions{pkg: "api", allowPrereleaseBump: true}); err != nil {
+			t.Fatalf("cmdRelease failed: %v", err)
+		}
+	})
+	if cfg, _ := loadConfig(p.config); cfg.Packages["api"] != "v2.0.0-rc.2" {
+		t.Errorf("expected api v2.0.0-rc.2, got %s", cfg.Packages["api"])
+	}
+}
+
+func TestNextPackageVersionsInvalid(t *testing.T) {
+	cfg := &config{Packages: map[string]string{"api": "bogus"}}
+	changes := []*changeset{{releases: []release{{name: "api", bump: patch}}}}
+
+	if _, err := nextPackageVersions(cfg, changes); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("expected ErrInvalidVersion, got %v", err)
+	}
+}
+
+func TestSeedPackages(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+
+	packages, err := seedPackages(p.root)
+	if err != nil {
+		t.Fatalf("seedPackages failed: %v", err)
+	}
+	if packages != nil {
+		t.Errorf("expected no packages outside a workspace, got %v", packages)
+	}
+
+	setupWorkspace(t, p, "api", "web")
+	packages, err = seedPackages(p.root)
+	if err != nil {
+		t.Fatalf("seedPackages failed: %v", err)
+	}
+	if len(packages) != 2 || packages["api"] != "v0.0.0" || packages["web"] != "v0.0.0" {
+		t.Errorf("unexpected seeded packages %v", packages)
+	}
+}
+
+func TestCmdInitSeedsPackages(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	setupWorkspace(t, p, "api")
+	os.RemoveAll(p.changesets)
+
+	captureStdout(func() {
+		if err := cmdInit(p, newScanner(""), initOptions{}); err != nil {
+			t.Fatalf("cmdInit failed: %v", err)
+		}
+	})
+
+	cfg, err := loadConfig(p.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Packages["api"] != "v0.0.0" {
+		t.Errorf("expected api seeded at v0.0.0, got %v", cfg.Packages)
+	}
+}
+
+func TestCmdNextPackage(t *testing.T) {
+	p := setupProject(t, "v1.0.0",
+		"---\napi: patch\n---\n\nAPI fix",
+		"---\nweb: major\n---\n\nWeb rewrite",
+	)
+	cfg, _ := loadConfig(p.config)
+	cfg.Packages = map[string]string{"api": "v0.4.0"}
+	saveConfig(p.config, cfg)
+
+	output := captureStdout(func() {
+		if err := cmdNext(p, nextOptions{pkg: "api"}); err != nil {
+			t.Fatalf("cmdNext failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "v0.4.1" {
+		t.Errorf("expected v0.4.1, got %q", output)
+	}
+}
+
+func TestCmdAddPackageScopedSlug(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	setupWorkspace(t, p, "api", "web")
+	if err := saveConfig(p.config, &config{Version: "v1.0.0", ScopedSlugs: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(func() {
+		if err := cmdAdd(p, newScanner(""), addOptions{bump: "minor", messages: []string{"New endpoint"}, pkg: "api"}); err != nil {
+			t.Fatalf("cmdAdd failed: %v", err)
+		}
+	})
+
+	changes, err := listChangesets(p.changes, parseOptions{})
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("expected 1 changeset, got %d (%v)", len(changes), err)
+	}
+	if !strings.HasPrefix(changes[0].slug, "api-") {
+		t.Errorf("expected slug prefixed with the package, got %q", changes[0].slug)
+	}
+	if b, ok := changes[0].bumpFor("api"); !ok || b != minor {
+		t.Errorf("expected a minor bump for api, got %v", changes[0].releases)
+	}
+}
+
+func TestCmdAddPackageUnscopedSlug(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	setupWorkspace(t, p, "api")
+
+	captureStdout(func() {
+		if err := cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fix"}, pkg: "api"}); err != nil {
+			t.Fatalf("cmdAdd failed: %v", err)
+		}
+	})
+
+	changes, _ := listChangesets(p.changes, parseOptions{})
+	if len(changes) != 1 || strings.HasPrefix(changes[0].slug, "api-") {
+		t.Fatalf("expected a plain slug without scopedSlugs, got %v", changes)
+	}
+}
+
+func TestCmdAddPackageNotMember(t *testing.T) {
+	p := setupProject(t, "v1.0.0")
+	setupWorkspace(t, p, "api")
+
+	if err := cmdAdd(p, newScanner(""), addOptions{bump: "patch", messages: []string{"Fix"}, pkg: "web"}); err == nil {
+		t.Fatal("expected error for a package outside the workspace")
+	}
+}